@@ -1,12 +1,17 @@
 package main
 
 import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"io"
 	"nitid/internal/cli"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 const exitOKCode = 0
@@ -195,6 +200,1614 @@ func TestCLI_FindAndSort(t *testing.T) {
 	}
 }
 
+func TestCLI_DateRangeFilters(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"daily", "--date", "2026-01-05"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "fresh note", "recent work"}, ""))
+
+	r := runCLI(t, dir, []string{"ls", "--created-after", "7d"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "fresh note") || strings.Contains(r.stdout, "Daily 2026-01-05") {
+		t.Fatalf("created-after output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--created-before", "2026-02-01"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Daily 2026-01-05") || strings.Contains(r.stdout, "fresh note") {
+		t.Fatalf("created-before output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"find", "work", "--updated-after", "1w"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "fresh note") {
+		t.Fatalf("find with updated-after unexpected: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"ls", "--updated-before", "yesterday-ish"}, ""))
+}
+
+func TestCLI_TagSetFilters(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "channels", "--tags", "go,concurrency", "body"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "pods", "--tags", "k8s,draft", "body"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "images", "--tags", "docker", "body"}, ""))
+
+	r := runCLI(t, dir, []string{"ls", "--tag", "go", "--tag", "concurrency"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "channels") || strings.Contains(r.stdout, "pods") {
+		t.Fatalf("all-of tag output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--any-tag", "k8s", "--any-tag", "docker", "--not-tag", "draft"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "images") || strings.Contains(r.stdout, "pods") || strings.Contains(r.stdout, "channels") {
+		t.Fatalf("any/not tag output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"find", "body", "--not-tag", "draft", "--tag", "docker"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "images") || strings.Contains(r.stdout, "pods") {
+		t.Fatalf("find tag filters unexpected: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"ls", "--not-status", "pending"}, ""))
+}
+
+func TestCLI_FuzzyFindAndSelectorSuggestions(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Worker leak", "goroutine stuck in retry loop"}, ""))
+
+	r := runCLI(t, dir, []string{"find", "goroutne"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "no matching notes found") {
+		t.Fatalf("exact find should miss typo: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"find", "--fuzzy", "goroutne"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Worker leak") {
+		t.Fatalf("fuzzy find output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"show", "workr"}, "")
+	mustFail(t, r)
+	if !strings.Contains(r.stderr, "did you mean") {
+		t.Fatalf("expected selector suggestion, stderr=%s", r.stderr)
+	}
+}
+
+func TestCLI_RegexFind(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Stack trace", "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d"}, ""))
+
+	r := runCLI(t, dir, []string{"find", "--regex", `main\.go:\d+`, "--in", "body"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Stack trace") || !strings.Contains(r.stdout, fmt.Sprintf("%d: \t/app/main.go:12 +0x1d", noteFileLine(t, dir, "/app/main.go:12"))) {
+		t.Fatalf("regex find output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"find", "--regex", "STACK", "--in", "title", "--case-sensitive"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "no matching notes found") {
+		t.Fatalf("case-sensitive regex should not match: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"find", "--regex", "("}, ""))
+	mustFail(t, runCLI(t, dir, []string{"find", "trace", "--in", "body"}, ""))
+}
+
+func noteFileLine(t *testing.T, dir, text string) int {
+	t.Helper()
+	var paths []string
+	err := filepath.WalkDir(filepath.Join(dir, "notes"), func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && strings.HasSuffix(path, ".md") {
+			paths = append(paths, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("walk notes: %v", err)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		for idx, line := range strings.Split(string(content), "\n") {
+			if strings.Contains(line, text) {
+				return idx + 1
+			}
+		}
+	}
+	t.Fatalf("no note line contains %q", text)
+	return 0
+}
+
+func TestCLI_FindSnippets(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Worker notes", "line one\nline two\nretry loop stuck\nline four"}, ""))
+
+	r := runCLI(t, dir, []string{"find", "retry"}, "")
+	mustOK(t, r)
+	line := noteFileLine(t, dir, "retry loop stuck")
+	if !strings.Contains(r.stdout, fmt.Sprintf("%d: retry loop stuck", line)) || strings.Contains(r.stdout, "line two") {
+		t.Fatalf("find snippet output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"find", "retry", "--context", "1"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, fmt.Sprintf("%d- line two", line-1)) || !strings.Contains(r.stdout, fmt.Sprintf("%d- line four", line+1)) || strings.Contains(r.stdout, "line one") {
+		t.Fatalf("find context output unexpected: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"find", "retry", "--context", "-2"}, ""))
+}
+
+func TestCLI_JSONOutput(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Json note", "--tags", "go", "json body"}, ""))
+
+	r := runCLI(t, dir, []string{"--format", "json", "ls", "--body"}, "")
+	mustOK(t, r)
+	var listed struct {
+		SchemaVersion int `json:"schema_version"`
+		Notes         []struct {
+			Ref     string   `json:"ref"`
+			Title   string   `json:"title"`
+			Tags    []string `json:"tags"`
+			RelPath string   `json:"rel_path"`
+			Body    *string  `json:"body"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil {
+		t.Fatalf("decode ls json: %v\n%s", err, r.stdout)
+	}
+	if listed.SchemaVersion != 1 || len(listed.Notes) != 1 || listed.Notes[0].Ref != "@1" || listed.Notes[0].Body == nil || *listed.Notes[0].Body != "json body" {
+		t.Fatalf("unexpected ls json: %+v", listed)
+	}
+
+	r = runCLI(t, dir, []string{"find", "json", "--format", "ndjson"}, "")
+	mustOK(t, r)
+	lines := strings.Split(strings.TrimSpace(r.stdout), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"type":"search_result"`) || !strings.Contains(lines[0], fmt.Sprintf(`"lines":[{"line":%d,`, noteFileLine(t, dir, "json body"))) {
+		t.Fatalf("unexpected find ndjson: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"show", "@1", "--format=json"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `"body": "json body"`) {
+		t.Fatalf("unexpected show json: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"--format", "json", "validate"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `"passed": true`) {
+		t.Fatalf("unexpected validate json: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"--format", "ndjson", "doctor"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `"type":"doctor_check"`) || !strings.Contains(r.stdout, `"type":"doctor_summary"`) {
+		t.Fatalf("unexpected doctor ndjson: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"--format", "json", "show", "nope"}, "")
+	mustFail(t, r)
+	var errObj map[string]any
+	if err := json.Unmarshal([]byte(r.stderr), &errObj); err != nil || errObj["type"] != "error" {
+		t.Fatalf("expected json error on stderr, got %q (%v)", r.stderr, err)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"--format", "xml", "ls"}, ""))
+
+	for _, args := range [][]string{
+		{"capture", "--title", "Scripted", "body", "--format", "json"},
+		{"--format", "ndjson", "tag", "@1", "add", "ops"},
+		{"daily", "--format=json"},
+		{"triage", "--format", "json"},
+	} {
+		r = runCLI(t, dir, args, "")
+		mustFail(t, r)
+		if r.stdout != "" || !strings.Contains(r.stderr, `"type":"error"`) || !strings.Contains(r.stderr, "does not support --format") {
+			t.Fatalf("%v should reject json output: stdout=%q stderr=%q", args, r.stdout, r.stderr)
+		}
+	}
+	r = runCLI(t, dir, []string{"ls", "--format", "json"}, "")
+	mustOK(t, r)
+	if strings.Contains(r.stdout, "Scripted") || strings.Contains(r.stdout, "Daily") {
+		t.Fatalf("rejected commands should not write notes: %s", r.stdout)
+	}
+}
+
+func TestCLI_OutputTemplates(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Template target", "--domain", "engineering", "body text"}, ""))
+
+	r := runCLI(t, dir, []string{"ls", "--template", "{{.Ref}} {{.Title}} ({{.Domain}}) {{date \"2006\" .CreatedAt}} {{truncate 6 .Note.Title}}"}, "")
+	mustOK(t, r)
+	if !strings.HasPrefix(r.stdout, "@1 Template target (engineering) 20") || !strings.Contains(r.stdout, "Tem...") {
+		t.Fatalf("inline template output unexpected: %s", r.stdout)
+	}
+
+	tmplPath := filepath.Join(dir, "row.tmpl")
+	if err := os.WriteFile(tmplPath, []byte("{{.RelPath}} {{ago .UpdatedAt}}"), 0o644); err != nil {
+		t.Fatalf("write template file: %v", err)
+	}
+	r = runCLI(t, dir, []string{"find", "body", "--template-file", tmplPath}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "notes/domains/engineering/") || !strings.Contains(r.stdout, "just now") {
+		t.Fatalf("template file output unexpected: %s", r.stdout)
+	}
+
+	configPath := filepath.Join(dir, ".nitid", "config.toml")
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	config = []byte(strings.Replace(string(config), "[output.templates]\n", "[output.templates]\nwiki = \"- [[{{.ID}}]] {{.Title}}\"\n", 1))
+	if err := os.WriteFile(configPath, config, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	r = runCLI(t, dir, []string{"ls", "--template", "wiki"}, "")
+	mustOK(t, r)
+	if !strings.HasPrefix(r.stdout, "- [[") || !strings.Contains(r.stdout, "]] Template target") {
+		t.Fatalf("named template output unexpected: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"ls", "--template", "missing-name"}, ""))
+	mustFail(t, runCLI(t, dir, []string{"ls", "--template", "{{.Nope"}, ""))
+}
+
+func TestCLI_ExportCSV(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--kind", "adr", "--title", "Use \"ULID\", not UUID", "--domain", "engineering", "--tags", "ids,storage", "decision body"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Plain note", "plain body"}, ""))
+
+	r := runCLI(t, dir, []string{"ls", "--kind", "adr", "--format", "json"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			RelPath string `json:"rel_path"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Notes) != 1 {
+		t.Fatalf("list adr json: %v %s", err, r.stdout)
+	}
+	notePath := filepath.Join(dir, listed.Notes[0].RelPath)
+	raw, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("read note: %v", err)
+	}
+	raw = []byte(strings.Replace(string(raw), "kind: \"adr\"\n", "kind: \"adr\"\nowner: platform\nreviewers:\n  - ana\n  - bo\n", 1))
+	if err := os.WriteFile(notePath, raw, 0o644); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"export", "csv", "--kind", "adr", "--columns", "title,tags,status,owner,reviewers,path"}, "")
+	mustOK(t, r)
+	lines := strings.Split(strings.TrimSpace(r.stdout), "\n")
+	if len(lines) != 2 || lines[0] != "title,tags,status,owner,reviewers,path" {
+		t.Fatalf("csv header/rows unexpected: %s", r.stdout)
+	}
+	if !strings.HasPrefix(lines[1], `"Use ""ULID"", not UUID",ids;storage,active,platform,ana;bo,notes/`) {
+		t.Fatalf("csv row unexpected: %s", lines[1])
+	}
+
+	r = runCLI(t, dir, []string{"export", "tsv", "--columns", "title,tags", "--separator", "|", "--no-header", "--tag", "ids"}, "")
+	mustOK(t, r)
+	if strings.TrimSpace(r.stdout) != `"Use ""ULID"", not UUID"`+"\tids|storage" {
+		t.Fatalf("tsv output unexpected: %q", r.stdout)
+	}
+
+	outPath := filepath.Join(dir, "all.csv")
+	mustOK(t, runCLI(t, dir, []string{"export", "csv", "--out", outPath}, ""))
+	exported, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if !strings.HasPrefix(string(exported), "id,title,domain,tags,status,kind,created,updated,path\n") || strings.Count(string(exported), "\n") != 3 {
+		t.Fatalf("default export unexpected: %s", exported)
+	}
+
+	r = runCLI(t, dir, []string{"export", "csv", "--columns", "title,ownr", "--out", filepath.Join(dir, "typo.csv")}, "")
+	mustFail(t, r)
+	if !strings.Contains(r.stderr, `unknown column "ownr"`) {
+		t.Fatalf("expected unknown column error: %s", r.stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "typo.csv")); !os.IsNotExist(err) {
+		t.Fatalf("rejected export should not create the output file: %v", err)
+	}
+	r = runCLI(t, dir, []string{"export", "csv", "--kind", "note", "--columns", "title,owner"}, "")
+	mustOK(t, r)
+	if r.stdout != "title,owner\nPlain note,\n" {
+		t.Fatalf("frontmatter key from other notes should export empty cells: %q", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"export", "csv", "--tag", "missing", "--columns", "id,reviewers"}, "")
+	mustOK(t, r)
+	if r.stdout != "id,reviewers\n" {
+		t.Fatalf("empty export unexpected: %q", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"export", "xlsx"}, ""))
+}
+
+func TestCLI_ExportHTML(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Worker pool", "--domain", "engineering", "--tags", "go", "Pool details"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Retry design", "--kind", "adr", "--tags", "go,k8s", "See [[Worker pool]] for context <script>"}, ""))
+
+	site := filepath.Join(dir, "site")
+	r := runCLI(t, dir, []string{"export", "html", site}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "exported 2 notes") {
+		t.Fatalf("unexpected export output: %s", r.stdout)
+	}
+
+	for _, rel := range []string{"index.html", "search-index.json", "domains/engineering.html", "tags/k8s.html", "kinds/adr.html", "tags/index.html"} {
+		if _, err := os.Stat(filepath.Join(site, rel)); err != nil {
+			t.Fatalf("expected %s: %v", rel, err)
+		}
+	}
+
+	var index []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	}
+	raw, err := os.ReadFile(filepath.Join(site, "search-index.json"))
+	if err != nil {
+		t.Fatalf("read search index: %v", err)
+	}
+	if err := json.Unmarshal(raw, &index); err != nil || len(index) != 2 {
+		t.Fatalf("search index unexpected: %v %s", err, raw)
+	}
+	pages := map[string]string{}
+	for _, entry := range index {
+		page, err := os.ReadFile(filepath.Join(site, entry.URL))
+		if err != nil {
+			t.Fatalf("read page %s: %v", entry.URL, err)
+		}
+		pages[entry.Title] = string(page)
+	}
+
+	var poolID string
+	for _, entry := range index {
+		if entry.Title == "Worker pool" {
+			poolID = entry.ID
+		}
+	}
+	if !strings.Contains(pages["Retry design"], `<a class="wikilink" href="`+poolID+`.html">Worker pool</a>`) || !strings.Contains(pages["Retry design"], "&lt;script&gt;") {
+		t.Fatalf("retry page missing link or escaping: %s", pages["Retry design"])
+	}
+	if !strings.Contains(pages["Worker pool"], "Backlinks") || !strings.Contains(pages["Worker pool"], ">Retry design</a>") {
+		t.Fatalf("worker page missing backlink: %s", pages["Worker pool"])
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"export", "html", site, "--kind", "adr"}, ""))
+	if _, err := os.Stat(filepath.Join(site, "domains", "engineering.html")); !os.IsNotExist(err) {
+		t.Fatalf("expected stale domain page to be removed, got %v", err)
+	}
+
+	other := filepath.Join(dir, "other")
+	if err := os.MkdirAll(other, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(other, "keep.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mustFail(t, runCLI(t, dir, []string{"export", "html", other}, ""))
+	mustFail(t, runCLI(t, dir, []string{"export", "html"}, ""))
+}
+
+func TestCLI_ImportMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	src := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	files := map[string]string{
+		"engineering/go/leak.md": "# Worker leak\n\nFound in #Concurrency code.",
+		"loose_thought.md":       "just an #idea",
+		"empty.md":               "",
+	}
+	for rel, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	mtime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(src, "engineering", "go", "leak.md"), mtime, mtime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	r := runCLI(t, dir, []string{"import", "markdown", src, "--dry-run"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "would import 2 notes, skipped 1") || !strings.Contains(r.stdout, "notes/inbox/") {
+		t.Fatalf("dry run output unexpected: %s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"ls"}, "")
+	mustOK(t, r)
+	if strings.Contains(r.stdout, "Worker leak") {
+		t.Fatalf("dry run wrote notes: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"import", "markdown", src}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "imported 2 notes") {
+		t.Fatalf("import output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--domain", "engineering", "--format", "json"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			Title     string   `json:"title"`
+			Tags      []string `json:"tags"`
+			CreatedAt string   `json:"created_at"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Notes) != 1 {
+		t.Fatalf("list imported: %v %s", err, r.stdout)
+	}
+	got := listed.Notes[0]
+	if got.Title != "Worker leak" || strings.Join(got.Tags, ",") != "concurrency,go" || got.CreatedAt != "2021-03-04T05:06:07Z" {
+		t.Fatalf("imported note unexpected: %+v", got)
+	}
+
+	r = runCLI(t, dir, []string{"import", "markdown", src}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "imported 0 notes, skipped 3") || !strings.Contains(r.stdout, "already imported") {
+		t.Fatalf("reimport should skip duplicates: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"import", "markdown", filepath.Join(dir, "notes")}, ""))
+	mustFail(t, runCLI(t, dir, []string{"import", "markdown"}, ""))
+}
+
+func TestCLI_ImportObsidian(t *testing.T) {
+	dir := t.TempDir()
+	src := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	files := map[string]string{
+		".obsidian/app.json":             "{}",
+		"Templates/Meeting.md":           "{{date}}",
+		"Engineering/Goroutine leaks.md": "---\ntags: [go, lang/go]\ncreated: 2024-01-02\nauthor: me\n---\nSee [[Worker Pool|the pool]], [[Missing note]] and ![[diagram.png]] #debug",
+		"Engineering/Worker Pool.md":     "---\naliases: [Pools]\n---\nPool details",
+		"Engineering/files/diagram.png":  "PNG",
+		"Daily/2024-05-01.md":            "- [ ] follow up on [[Pools]]",
+		"Ideas.md":                       "loose idea",
+	}
+	for rel, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	r := runCLI(t, dir, []string{"import", "obsidian", src, "--nested-tags", "leaf"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "imported 4 notes and 1 assets") {
+		t.Fatalf("import output unexpected: %s", r.stdout)
+	}
+	for _, want := range []string{`unresolved link [[Missing note]]`, `property "author" was not imported`} {
+		if !strings.Contains(r.stdout, want) {
+			t.Fatalf("expected warning %q in: %s", want, r.stdout)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "assets", "diagram.png")); err != nil {
+		t.Fatalf("expected copied attachment: %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--format", "json", "--body"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			ID        string   `json:"id"`
+			Title     string   `json:"title"`
+			Domain    string   `json:"domain"`
+			Kind      string   `json:"kind"`
+			Tags      []string `json:"tags"`
+			Links     []string `json:"links"`
+			CreatedAt string   `json:"created_at"`
+			Body      string   `json:"body"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	byTitle := map[string]int{}
+	for i, note := range listed.Notes {
+		byTitle[note.Title] = i
+	}
+	pool := listed.Notes[byTitle["Worker Pool"]]
+	leaks := listed.Notes[byTitle["Goroutine leaks"]]
+	daily, ok := byTitle["Daily 2024-05-01"]
+	if !ok || listed.Notes[daily].Kind != "daily" {
+		t.Fatalf("expected daily note, got %+v", listed.Notes)
+	}
+	if leaks.Domain != "engineering" || strings.Join(leaks.Tags, ",") != "debug,go" || leaks.CreatedAt != "2024-01-02T00:00:00Z" {
+		t.Fatalf("leaks note unexpected: %+v", leaks)
+	}
+	if !strings.Contains(leaks.Body, "[["+pool.ID+"|the pool]]") || !strings.Contains(leaks.Body, "![diagram.png](assets/diagram.png)") {
+		t.Fatalf("leaks body not translated: %s", leaks.Body)
+	}
+	if len(leaks.Links) != 1 || leaks.Links[0] != pool.ID {
+		t.Fatalf("leaks links unexpected: %v", leaks.Links)
+	}
+	if !strings.Contains(listed.Notes[daily].Body, "[["+pool.ID+"|Pools]]") {
+		t.Fatalf("alias link not resolved: %s", listed.Notes[daily].Body)
+	}
+	if _, ok := byTitle["Meeting"]; ok {
+		t.Fatalf("templates folder should be skipped")
+	}
+
+	r = runCLI(t, dir, []string{"import", "obsidian", src}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "imported 0 notes, skipped 4") {
+		t.Fatalf("reimport should skip everything: %s", r.stdout)
+	}
+	mustFail(t, runCLI(t, dir, []string{"import", "obsidian", src, "--nested-tags", "bogus"}, ""))
+}
+
+func TestCLI_ImportEnex(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	data := []byte("PNGDATA")
+	enex := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">
+<en-export export-date="20240101T000000Z" application="Evernote" version="10.0">
+<note>
+<title>Launch checklist</title>
+<created>20200102T030405Z</created>
+<updated>20200103T030405Z</updated>
+<tag>Project X</tag>
+<content><![CDATA[<?xml version="1.0" encoding="UTF-8"?><en-note><div><en-todo checked="true"/>Book room</div><div><en-todo/>Send invite</div><div><en-media hash="` + fmt.Sprintf("%x", md5.Sum(data)) + `" type="image/png"/></div></en-note>]]></content>
+<resource><data encoding="base64">` + base64.StdEncoding.EncodeToString(data) + `</data><mime>image/png</mime><resource-attributes><file-name>diagram.png</file-name></resource-attributes></resource>
+</note>
+</en-export>`
+	enexPath := filepath.Join(dir, "Work Projects.enex")
+	if err := os.WriteFile(enexPath, []byte(enex), 0o644); err != nil {
+		t.Fatalf("write enex: %v", err)
+	}
+
+	r := runCLI(t, dir, []string{"import", "enex", enexPath}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "imported 1 notes and 1 assets") {
+		t.Fatalf("import output unexpected: %s", r.stdout)
+	}
+	asset, err := os.ReadFile(filepath.Join(dir, "assets", "diagram.png"))
+	if err != nil || string(asset) != "PNGDATA" {
+		t.Fatalf("asset not decoded: %v %q", err, asset)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--format", "json", "--body"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			Title     string   `json:"title"`
+			Domain    string   `json:"domain"`
+			Tags      []string `json:"tags"`
+			CreatedAt string   `json:"created_at"`
+			UpdatedAt string   `json:"updated_at"`
+			Body      string   `json:"body"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Notes) != 1 {
+		t.Fatalf("decode: %v %s", err, r.stdout)
+	}
+	note := listed.Notes[0]
+	if note.Domain != "work-projects" || strings.Join(note.Tags, ",") != "project-x" {
+		t.Fatalf("metadata unexpected: %+v", note)
+	}
+	if note.CreatedAt != "2020-01-02T03:04:05Z" || note.UpdatedAt != "2020-01-03T03:04:05Z" {
+		t.Fatalf("times not preserved: %+v", note)
+	}
+	if note.Body != "- [x] Book room\n- [ ] Send invite\n![diagram.png](assets/diagram.png)" {
+		t.Fatalf("body unexpected: %q", note.Body)
+	}
+
+	r = runCLI(t, dir, []string{"import", "enex", enexPath, "--domain", "archive-2020", "--dry-run"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "notes/domains/archive-2020/") {
+		t.Fatalf("domain override not applied: %s", r.stdout)
+	}
+
+	badPath := filepath.Join(dir, "bad.enex")
+	if err := os.WriteFile(badPath, []byte("<html></html>"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mustFail(t, runCLI(t, dir, []string{"import", "enex", badPath}, ""))
+}
+
+func TestCLI_NDJSONRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Worker pool", "--domain", "engineering", "--tags", "go", "Pool details"}, ""))
+
+	r := runCLI(t, dir, []string{"ls", "--format", "json"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			ID      string `json:"id"`
+			RelPath string `json:"rel_path"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Notes) != 1 {
+		t.Fatalf("list json: %v %s", err, r.stdout)
+	}
+	notePath := filepath.Join(dir, listed.Notes[0].RelPath)
+	raw, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("read note: %v", err)
+	}
+	raw = []byte(strings.Replace(string(raw), "links: []\n", "links: []\ndue: 2026-10-20\nowner: platform\nrating: 4\n", 1))
+	if err := os.WriteFile(notePath, raw, 0o644); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"export", "ndjson"}, "")
+	mustOK(t, r)
+	exported := strings.TrimSpace(r.stdout)
+	var record struct {
+		Frontmatter map[string]any `json:"frontmatter"`
+		Body        string         `json:"body"`
+		RelPath     string         `json:"rel_path"`
+	}
+	if err := json.Unmarshal([]byte(exported), &record); err != nil {
+		t.Fatalf("decode record: %v %s", err, exported)
+	}
+	if record.Frontmatter["owner"] != "platform" || record.Frontmatter["rating"] != float64(4) || record.Frontmatter["due"] != "2026-10-20" || record.Frontmatter["domain"] != "engineering" {
+		t.Fatalf("frontmatter not exported: %v", record.Frontmatter)
+	}
+	if record.Body != "Pool details" || record.RelPath != listed.Notes[0].RelPath {
+		t.Fatalf("record unexpected: %+v", record)
+	}
+
+	other := t.TempDir()
+	mustOK(t, runCLI(t, other, []string{"init", "."}, ""))
+	r = runCLI(t, other, []string{"import", "ndjson"}, exported+"\nnot json\n")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "imported 1 notes, skipped 1") {
+		t.Fatalf("import output unexpected: %s", r.stdout)
+	}
+	copied, err := os.ReadFile(filepath.Join(other, listed.Notes[0].RelPath))
+	if err != nil {
+		t.Fatalf("read imported note: %v", err)
+	}
+	if string(copied) != string(raw) {
+		t.Fatalf("round trip not lossless:\n%s\n---\n%s", copied, raw)
+	}
+
+	var mutable map[string]any
+	if err := json.Unmarshal([]byte(exported), &mutable); err != nil {
+		t.Fatalf("decode record: %v", err)
+	}
+	frontmatter := mutable["frontmatter"].(map[string]any)
+	frontmatter["title"] = "Older pool"
+	frontmatter["updated_at"] = "1999-01-01T00:00:00Z"
+	olderJSON, err := json.Marshal(mutable)
+	if err != nil {
+		t.Fatalf("encode record: %v", err)
+	}
+	older := string(olderJSON)
+	r = runCLI(t, other, []string{"import", "ndjson", "--on-conflict", "newer-wins"}, older)
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "existing note is not older") {
+		t.Fatalf("newer-wins should keep existing note: %s", r.stdout)
+	}
+	mustOK(t, runCLI(t, other, []string{"import", "ndjson"}, exported))
+
+	retitled := strings.Replace(exported, `"title":"Worker pool"`, `"title":"Renamed pool"`, 1)
+	ndjsonPath := filepath.Join(other, "notes.ndjson")
+	if err := os.WriteFile(ndjsonPath, []byte(retitled+"\n"), 0o644); err != nil {
+		t.Fatalf("write ndjson: %v", err)
+	}
+	r = runCLI(t, other, []string{"import", "ndjson", ndjsonPath, "--on-conflict", "overwrite"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "replaced") {
+		t.Fatalf("overwrite output unexpected: %s", r.stdout)
+	}
+	r = runCLI(t, other, []string{"show", listed.Notes[0].ID}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Renamed pool") {
+		t.Fatalf("note not overwritten: %s", r.stdout)
+	}
+	if _, err := os.Stat(filepath.Join(other, listed.Notes[0].RelPath)); !os.IsNotExist(err) {
+		t.Fatalf("old file should be replaced: %v", err)
+	}
+
+	mustFail(t, runCLI(t, other, []string{"import", "ndjson", "--on-conflict", "merge"}, exported))
+}
+
+func TestCLI_BackupVerifyRestore(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	mustOK(t, runCLI(t, dir, []string{"capture", "--title", "Worker pool", "--domain", "engineering", "Pool details"}, ""))
+	if err := os.WriteFile(filepath.Join(dir, "assets", "diagram.png"), []byte("PNGDATA"), 0o644); err != nil {
+		t.Fatalf("write asset: %v", err)
+	}
+
+	r := runCLI(t, dir, []string{"doctor"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "no backup yet") {
+		t.Fatalf("doctor should mention missing backup: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"backup"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "backed up 3 files") || !strings.Contains(r.stdout, filepath.Join(".nitid", "backups", "nitid-backup-")) {
+		t.Fatalf("default backup output unexpected: %s", r.stdout)
+	}
+
+	archive := filepath.Join(t.TempDir(), "vault.tar.gz")
+	r = runCLI(t, dir, []string{"backup", "--out", archive, "--format", "json"}, "")
+	mustOK(t, r)
+	var created struct {
+		Backup struct {
+			Files         int `json:"files"`
+			SchemaVersion int `json:"schema_version"`
+		} `json:"backup"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &created); err != nil || created.Backup.Files != 3 || created.Backup.SchemaVersion != 1 {
+		t.Fatalf("backup json unexpected: %v %s", err, r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"doctor"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "[ok] last backup: just now ("+archive+")") {
+		t.Fatalf("doctor should report last backup: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"backup", "verify", archive}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "backup verified") {
+		t.Fatalf("verify output unexpected: %s", r.stdout)
+	}
+
+	target := filepath.Join(t.TempDir(), "restored")
+	r = runCLI(t, dir, []string{"backup", "restore", archive, target}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "restored 3 files") {
+		t.Fatalf("restore output unexpected: %s", r.stdout)
+	}
+	if asset, err := os.ReadFile(filepath.Join(target, "assets", "diagram.png")); err != nil || string(asset) != "PNGDATA" {
+		t.Fatalf("asset not restored: %v %q", err, asset)
+	}
+	r = runCLI(t, target, []string{"ls"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Worker pool") {
+		t.Fatalf("restored vault missing note: %s", r.stdout)
+	}
+	mustOK(t, runCLI(t, target, []string{"validate"}, ""))
+	if _, err := os.Stat(filepath.Join(target, "notes", "inbox")); err != nil {
+		t.Fatalf("restored vault missing structure: %v", err)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"backup", "restore", archive, target}, ""))
+	notArchive := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notArchive, []byte("plain text"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mustFail(t, runCLI(t, dir, []string{"backup", "verify", notArchive}, ""))
+}
+
+func TestCLI_VaultTemplates(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	r := runCLI(t, dir, []string{"templates", "new", "standup", "--from", "meeting"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "created .nitid/templates/standup.md") {
+		t.Fatalf("templates new output unexpected: %s", r.stdout)
+	}
+	mustFail(t, runCLI(t, dir, []string{"templates", "new", "standup"}, ""))
+
+	templatesDir := filepath.Join(dir, ".nitid", "templates")
+	standup := "---\nkind: note\ntitle: Daily standup\ndomain: team\ntags: [standup, Sync]\n---\n\n## Yesterday\n\n## Today\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "standup.md"), []byte(standup), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	adr := "---\nkind: adr\n---\n\n## Status\n\nProposed\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "adr.md"), []byte(adr), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templatesDir, "broken.md"), []byte("---\nkind: essay\n---\nbody\n"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"templates"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "bug") || !strings.Contains(r.stdout, "built-in") {
+		t.Fatalf("built-in templates missing: %s", r.stdout)
+	}
+	if !strings.Contains(r.stdout, ".nitid/templates/standup.md") || !strings.Contains(r.stdout, ".nitid/templates/adr.md, overrides built-in") {
+		t.Fatalf("template sources missing: %s", r.stdout)
+	}
+	if !strings.Contains(r.stderr, "broken.md: invalid kind") {
+		t.Fatalf("broken template warning missing: %s", r.stderr)
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"new", "standup", "--tags", "monday", "Shipped importer"}, ""))
+	r = runCLI(t, dir, []string{"ls", "--domain", "team", "--format", "json", "--body"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			Title string   `json:"title"`
+			Tags  []string `json:"tags"`
+			Body  string   `json:"body"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Notes) != 1 {
+		t.Fatalf("decode: %v %s", err, r.stdout)
+	}
+	note := listed.Notes[0]
+	if note.Title != "Daily standup" || strings.Join(note.Tags, ",") != "monday,standup,sync" {
+		t.Fatalf("template defaults not applied: %+v", note)
+	}
+	if note.Body != "## Yesterday\n\n## Today\n\nShipped importer" {
+		t.Fatalf("template body unexpected: %q", note.Body)
+	}
+
+	r = runCLI(t, dir, []string{"templates", "show", "adr"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "source: .nitid/templates/adr.md") || !strings.Contains(r.stdout, "## Status") {
+		t.Fatalf("override not shown: %s", r.stdout)
+	}
+	mustOK(t, runCLI(t, dir, []string{"new", "adr", "--title", "Adopt NDJSON"}, ""))
+	r = runCLI(t, dir, []string{"find", "Proposed", "--kind", "adr"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Adopt NDJSON") {
+		t.Fatalf("vault adr template not used: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"templates", "new", "Bad Name"}, ""))
+}
+
+func TestCLI_TemplateVariablesAndPrompts(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	t.Setenv("USER", "ana")
+
+	templatesDir := filepath.Join(dir, ".nitid", "templates")
+	if err := os.MkdirAll(templatesDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	retro := "---\ntitle: \"Retro {{date}}\"\ndomain: team\n---\n\n# {{title}}\n\nFacilitator: {{user}} ({{domain}})\nGoal: {{prompt \"Sprint goal\"}}\nMood: {{prompt \"Mood\"}}\nKeep {{unknown}} as-is.\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "retro.md"), []byte(retro), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	r := runCLI(t, dir, []string{"templates", "show", "retro"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "prompts: Sprint goal, Mood") {
+		t.Fatalf("prompts not listed: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"new", "retro", "--var", "sprint-goal=Ship importers"}, "great\n")
+	mustOK(t, r)
+	if !strings.Contains(r.stderr, "Mood: ") || strings.Contains(r.stderr, "Sprint goal:") {
+		t.Fatalf("only unanswered prompts should be asked: %q", r.stderr)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--domain", "team", "--format", "json", "--body"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			Title string `json:"title"`
+			Body  string `json:"body"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Notes) != 1 {
+		t.Fatalf("decode: %v %s", err, r.stdout)
+	}
+	today := time.Now().Format("2006-01-02")
+	note := listed.Notes[0]
+	if note.Title != "Retro "+today {
+		t.Fatalf("title not expanded: %q", note.Title)
+	}
+	want := "# Retro " + today + "\n\nFacilitator: ana (team)\nGoal: Ship importers\nMood: great\nKeep {{unknown}} as-is."
+	if note.Body != want {
+		t.Fatalf("body not expanded:\n%q\nwant\n%q", note.Body, want)
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"new", "meeting", "--domain", "team", "--var", "date=2026-03-01"}, "Ana, Bo\n"))
+	r = runCLI(t, dir, []string{"find", "Meeting notes", "--format", "json", "--body"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `## Date\n\n2026-03-01 `) || !strings.Contains(r.stdout, `## Attendees\n\nAna, Bo`) {
+		t.Fatalf("meeting template not expanded: %s", r.stdout)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"new", "retro", "--var", "novalue"}, ""))
+}
+
+func TestCLI_DailyCarryOver(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	dailyBody := func(date string) (string, string) {
+		t.Helper()
+		r := runCLI(t, dir, []string{"ls", "--kind", "daily", "--format", "json", "--body"}, "")
+		mustOK(t, r)
+		var listed struct {
+			Notes []struct {
+				ID      string   `json:"id"`
+				Title   string   `json:"title"`
+				Links   []string `json:"links"`
+				RelPath string   `json:"rel_path"`
+				Body    string   `json:"body"`
+			} `json:"notes"`
+		}
+		if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil {
+			t.Fatalf("decode: %v %s", err, r.stdout)
+		}
+		for _, note := range listed.Notes {
+			if note.Title == "Daily "+date {
+				return note.Body, filepath.Join(dir, note.RelPath)
+			}
+		}
+		t.Fatalf("daily %s not found: %s", date, r.stdout)
+		return "", ""
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"daily", "--date", "2026-03-01"}, ""))
+	_, firstPath := dailyBody("2026-03-01")
+	raw, err := os.ReadFile(firstPath)
+	if err != nil {
+		t.Fatalf("read daily: %v", err)
+	}
+	raw = []byte(strings.Replace(string(raw), "- [ ] Top priority 2", "- [x] Ship importer\n- [ ] Review backup PR\n  - [ ] Reply to Bo\n- [ ] Call vendor due:tomorrow\n\n```\n- [ ] not a task\n```", 1))
+	if err := os.WriteFile(firstPath, raw, 0o644); err != nil {
+		t.Fatalf("write daily: %v", err)
+	}
+
+	r := runCLI(t, dir, []string{"daily", "--date", "2026-03-03"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "copied 3 open tasks from notes/daily/2026/03/") {
+		t.Fatalf("carry-over output unexpected: %s", r.stdout)
+	}
+	body, _ := dailyBody("2026-03-03")
+	if !strings.Contains(body, "## Plan (2026-03-03)\n\nCarried over from [[") || !strings.Contains(body, "|Daily 2026-03-01]]:\n\n- [ ] Review backup PR\n- [ ] Reply to Bo\n- [ ] Call vendor due:2026-03-02\n\n## Notes") {
+		t.Fatalf("carried tasks missing: %q", body)
+	}
+	if strings.Contains(body, "Top priority") || strings.Contains(body, "Ship importer") || strings.Contains(body, "not a task") {
+		t.Fatalf("unexpected tasks carried: %q", body)
+	}
+
+	config, err := os.ReadFile(filepath.Join(dir, ".nitid", "config.toml"))
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	config = []byte(strings.Replace(string(config), "[daily]\n", "[daily]\ncarry_over = \"move\"\n", 1))
+	if err := os.WriteFile(filepath.Join(dir, ".nitid", "config.toml"), config, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"daily", "--date", "2026-03-04"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "moved 3 open tasks") {
+		t.Fatalf("move output unexpected: %s", r.stdout)
+	}
+	previous, _ := dailyBody("2026-03-03")
+	if strings.Contains(previous, "- [ ] Review backup PR") {
+		t.Fatalf("moved tasks should leave the source day: %q", previous)
+	}
+	body, _ = dailyBody("2026-03-04")
+	if !strings.Contains(body, "|Daily 2026-03-03]]:\n\n- [ ] Review backup PR") {
+		t.Fatalf("moved tasks missing: %q", body)
+	}
+	r = runCLI(t, dir, []string{"agenda", "--date", "2026-03-04", "--format", "json"}, "")
+	mustOK(t, r)
+	var agenda struct {
+		Agenda struct {
+			Overdue []struct {
+				NoteTitle string `json:"note_title"`
+				Text      string `json:"text"`
+				Due       string `json:"due"`
+			} `json:"overdue"`
+		} `json:"agenda"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &agenda); err != nil {
+		t.Fatalf("decode agenda: %v %s", err, r.stdout)
+	}
+	carried := false
+	for _, task := range agenda.Agenda.Overdue {
+		if task.NoteTitle == "Daily 2026-03-04" {
+			carried = task.Text == "Call vendor due:2026-03-02" && task.Due == "2026-03-02"
+		}
+	}
+	if !carried {
+		t.Fatalf("carried task should keep its due date: %s", r.stdout)
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"daily", "--date", "2026-03-05", "--carry-over", "off"}, ""))
+	body, _ = dailyBody("2026-03-05")
+	if !strings.Contains(body, "- [ ] Top priority 1") || strings.Contains(body, "Carried over") {
+		t.Fatalf("carry-over off should use the default plan: %q", body)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"daily", "--date", "2026-03-06", "--carry-over", "sometimes"}, ""))
+}
+
+func TestCLI_PeriodicNotes(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	records := []string{
+		`{"frontmatter":{"id":"01JNA0000000000000000000D1","title":"Daily 2026-03-02","created_at":"2026-03-02T09:00:00Z","kind":"daily","tags":["daily"]},"body":"## Plan (2026-03-02)\n\n- [x] Ship importer\n- [ ] Review PR\n\n## Wins\n\n- Importer merged"}`,
+		`{"frontmatter":{"id":"01JNA0000000000000000000D2","title":"Daily 2026-03-04","created_at":"2026-03-04T09:00:00Z","kind":"daily","tags":["daily"]},"body":"## Plan (2026-03-04)\n\n- [x] Write docs\n\n## Wins\n\nHelped Bo debug CI"}`,
+		`{"frontmatter":{"id":"01JNA0000000000000000000D3","title":"Daily 2026-03-09","created_at":"2026-03-09T09:00:00Z","kind":"daily","tags":["daily"]},"body":"## Wins\n\n- Next week win"}`,
+		`{"frontmatter":{"id":"01JNA0000000000000000000N1","title":"Retry design","created_at":"2026-03-03T10:00:00Z","domain":"engineering"},"body":"design"}`,
+		`{"frontmatter":{"id":"01JNA0000000000000000000N2","title":"Old spike","created_at":"2026-01-03T10:00:00Z","updated_at":"2026-03-05T10:00:00Z","domain":"engineering","status":"archived"},"body":"spike"}`,
+	}
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, strings.Join(records, "\n")))
+
+	r := runCLI(t, dir, []string{"weekly", "--date", "2026-03-04"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "saved notes/weekly/2026/") || !strings.Contains(r.stdout, "rollup 2026-03-02 to 2026-03-08: 2 daily notes, 1 created, 1 archived, 2 completed tasks, 2 wins") {
+		t.Fatalf("weekly output unexpected: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--kind", "weekly", "--format", "json", "--body"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Notes []struct {
+			Title     string   `json:"title"`
+			CreatedAt string   `json:"created_at"`
+			Status    string   `json:"status"`
+			Links     []string `json:"links"`
+			Body      string   `json:"body"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Notes) != 1 {
+		t.Fatalf("decode: %v %s", err, r.stdout)
+	}
+	weekly := listed.Notes[0]
+	if weekly.Title != "Weekly 2026-W10" || weekly.CreatedAt != "2026-03-02T09:00:00Z" || weekly.Status != "active" || len(weekly.Links) != 2 {
+		t.Fatalf("weekly metadata unexpected: %+v", weekly)
+	}
+	for _, want := range []string{
+		"## Rollup (2026-03-02 to 2026-03-08)",
+		"- [[01JNA0000000000000000000D1|Daily 2026-03-02]]",
+		"- [[01JNA0000000000000000000N1|Retry design]] (engineering)",
+		"### Archived (1)\n\n- [[01JNA0000000000000000000N2|Old spike]]",
+		"### Completed tasks (2)\n\n- Ship importer ([[01JNA0000000000000000000D1|Daily 2026-03-02]])",
+		"- Helped Bo debug CI ([[01JNA0000000000000000000D2|Daily 2026-03-04]])",
+	} {
+		if !strings.Contains(weekly.Body, want) {
+			t.Fatalf("weekly body missing %q:\n%s", want, weekly.Body)
+		}
+	}
+	if strings.Contains(weekly.Body, "Review PR") || strings.Contains(weekly.Body, "Next week win") {
+		t.Fatalf("weekly rollup leaked items: %s", weekly.Body)
+	}
+
+	r = runCLI(t, dir, []string{"tasks", "--done", "--format", "json"}, "")
+	mustOK(t, r)
+	if strings.Contains(r.stdout, "Weekly 2026-W10") {
+		t.Fatalf("rollup should not add done tasks: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"weekly", "--date", "2026-03-08"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "weekly note already exists") {
+		t.Fatalf("expected existing weekly note: %s", r.stdout)
+	}
+
+	templatesDir := filepath.Join(dir, ".nitid", "templates")
+	if err := os.MkdirAll(templatesDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templatesDir, "quarterly.md"), []byte("---\ntags: [review]\n---\n\n# Review {{period}}\n\n{{rollup}}\n"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	mustOK(t, runCLI(t, dir, []string{"quarterly", "--date", "2026-02-10"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"monthly", "--date", "2026-03-31"}, ""))
+	r = runCLI(t, dir, []string{"ls", "--kind", "quarterly", "--format", "json", "--body"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `"title": "Quarterly 2026-Q1"`) || !strings.Contains(r.stdout, `# Review 2026-Q1\n\n### Daily notes (3)`) || !strings.Contains(r.stdout, `"review"`) {
+		t.Fatalf("quarterly template not applied: %s", r.stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes", "monthly", "2026")); err != nil {
+		t.Fatalf("monthly note not routed: %v", err)
+	}
+	mustOK(t, runCLI(t, dir, []string{"validate"}, ""))
+}
+
+func TestCLI_Tasks(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	records := []string{
+		`{"frontmatter":{"id":"01JNA0000000000000000000T1","title":"Release plan","created_at":"2026-03-02T09:00:00Z","domain":"engineering","tags":["release"]},"body":"## Checklist\n\n- [ ] Tag release due:2026-03-10 @ana !high\n- [x] Freeze branch\n\n## Later\n\n- [ ] Write announcement @bo"}`,
+		`{"frontmatter":{"id":"01JNB0000000000000000000T2","title":"Groceries","created_at":"2026-03-03T09:00:00Z","domain":"personal"},"body":"- [ ] Buy coffee\n\n\u0060\u0060\u0060\n- [ ] not a task\n\u0060\u0060\u0060"}`,
+	}
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, strings.Join(records, "\n")))
+
+	ref := func(prefix, text string) string {
+		return fmt.Sprintf("%s:%d", prefix, noteFileLine(t, dir, text))
+	}
+	tagRef, freezeRef := ref("01JNA000", "Tag release"), ref("01JNA000", "Freeze branch")
+
+	r := runCLI(t, dir, []string{"tasks"}, "")
+	mustOK(t, r)
+	for _, want := range []string{
+		fmt.Sprintf("%-16s  [ ] Tag release due:2026-03-10 @ana !high  (Release plan > Checklist)", tagRef),
+		fmt.Sprintf("%-16s  [x] Freeze branch", freezeRef),
+		fmt.Sprintf("%-16s  [ ] Write announcement @bo  (Release plan > Later)", ref("01JNA000", "Write announcement")),
+		fmt.Sprintf("%-16s  [ ] Buy coffee  (Groceries)", ref("01JNB000", "Buy coffee")),
+	} {
+		if !strings.Contains(r.stdout, want) {
+			t.Fatalf("tasks output missing %q:\n%s", want, r.stdout)
+		}
+	}
+	if strings.Contains(r.stdout, "not a task") {
+		t.Fatalf("tasks parsed a fenced checkbox: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"tasks", "--done"}, "")
+	mustOK(t, r)
+	if strings.Count(r.stdout, "\n") != 1 || !strings.Contains(r.stdout, "Freeze branch") {
+		t.Fatalf("expected only done task: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"tasks", "--open", "--domain", "engineering", "--owner", "@ana", "--format", "json"}, "")
+	mustOK(t, r)
+	var listed struct {
+		Tasks []struct {
+			Ref      string   `json:"ref"`
+			NoteID   string   `json:"note_id"`
+			Line     int      `json:"line"`
+			Heading  string   `json:"heading"`
+			Due      string   `json:"due"`
+			Owners   []string `json:"owners"`
+			Priority string   `json:"priority"`
+			Done     bool     `json:"done"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &listed); err != nil || len(listed.Tasks) != 1 {
+		t.Fatalf("decode: %v %s", err, r.stdout)
+	}
+	task := listed.Tasks[0]
+	if task.Ref != tagRef || task.Line != noteFileLine(t, dir, "Tag release") || task.Heading != "Checklist" || task.Due != "2026-03-10" || task.Priority != "high" || len(task.Owners) != 1 || task.Done {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"tasks", "--priority", "urgent"}, ""))
+	mustFail(t, runCLI(t, dir, []string{"tasks", "done", "01JNA000"}, ""))
+	mustFail(t, runCLI(t, dir, []string{"tasks", "done", "01JNA000:2"}, ""))
+	r = runCLI(t, dir, []string{"tasks", "done", freezeRef}, "")
+	mustFail(t, r)
+	if !strings.Contains(r.stderr, "already done") {
+		t.Fatalf("expected already done error: %s", r.stderr)
+	}
+
+	r = runCLI(t, dir, []string{"tasks", "done", tagRef}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `completed "Tag release due:2026-03-10 @ana !high" in 01JNA0000000000000000000T1`) {
+		t.Fatalf("unexpected done output: %s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"show", "01JNA000", "--raw"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "- [x] Tag release due:2026-03-10 @ana !high\n- [x] Freeze branch") {
+		t.Fatalf("task not ticked in place: %s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"tasks", "--open"}, "")
+	mustOK(t, r)
+	if strings.Contains(r.stdout, "Tag release") || !strings.Contains(r.stdout, "Buy coffee") {
+		t.Fatalf("unexpected open tasks: %s", r.stdout)
+	}
+	mustOK(t, runCLI(t, dir, []string{"validate"}, ""))
+}
+
+func TestCLI_Agenda(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	records := []string{
+		`{"frontmatter":{"id":"01JNA0000000000000000000T1","title":"Release plan","created_at":"2026-03-01T09:00:00Z"},"body":"## Checklist\n\n- [ ] Tag release due:2026-03-04 @ana\n- [ ] Publish notes due:2026-03-05\n- [ ] Retro due:2026-03-09\n- [ ] Next quarter due:2026-04-01\n- [x] Freeze due:2026-03-05"}`,
+		`{"frontmatter":{"id":"01JNB0000000000000000000D1","title":"Daily 2026-03-06","created_at":"2026-03-06T09:00:00Z","kind":"daily"},"body":"- [ ] Top priority 1"}`,
+	}
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, strings.Join(records, "\n")))
+
+	ref := func(text string) string {
+		return fmt.Sprintf("01JNA000:%d", noteFileLine(t, dir, text))
+	}
+
+	r := runCLI(t, dir, []string{"agenda", "--date", "2026-03-05"}, "")
+	mustOK(t, r)
+	want := strings.Join([]string{
+		"Overdue (1)",
+		fmt.Sprintf("  2026-03-04  %-16s  [ ] Tag release due:2026-03-04 @ana  (Release plan > Checklist)", ref("Tag release")),
+		"Today 2026-03-05 (1)",
+		fmt.Sprintf("  2026-03-05  %-16s  [ ] Publish notes due:2026-03-05  (Release plan > Checklist)", ref("Publish notes")),
+		"Upcoming to 2026-03-12 (2)",
+		"  2026-03-06  01JNB000          Daily 2026-03-06",
+		fmt.Sprintf("  2026-03-09  %-16s  [ ] Retro due:2026-03-09  (Release plan > Checklist)", ref("Retro")),
+	}, "\n") + "\n"
+	if r.stdout != want {
+		t.Fatalf("agenda output unexpected:\n%s\nwant:\n%s", r.stdout, want)
+	}
+
+	r = runCLI(t, dir, []string{"agenda", "--date", "2026-03-05", "--days", "0", "--format", "json"}, "")
+	mustOK(t, r)
+	var out struct {
+		Agenda struct {
+			Today   string `json:"today"`
+			End     string `json:"end"`
+			Overdue []struct {
+				Ref string `json:"ref"`
+				Due string `json:"due"`
+			} `json:"overdue"`
+			DueToday []struct{} `json:"due_today"`
+			Upcoming []struct{} `json:"upcoming"`
+			Dailies  []struct{} `json:"dailies"`
+		} `json:"agenda"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &out); err != nil {
+		t.Fatalf("decode: %v %s", err, r.stdout)
+	}
+	if out.Agenda.Today != "2026-03-05" || out.Agenda.End != "2026-03-05" || len(out.Agenda.Overdue) != 1 || out.Agenda.Overdue[0].Ref != ref("Tag release") || len(out.Agenda.DueToday) != 1 || len(out.Agenda.Upcoming) != 0 || len(out.Agenda.Dailies) != 0 {
+		t.Fatalf("unexpected agenda json: %+v", out.Agenda)
+	}
+
+	icsPath := filepath.Join(dir, "agenda.ics")
+	r = runCLI(t, dir, []string{"agenda", "--date", "2026-03-05", "--ics", icsPath}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "wrote 4 events to") {
+		t.Fatalf("unexpected ics output: %s", r.stdout)
+	}
+	ics, err := os.ReadFile(icsPath)
+	if err != nil {
+		t.Fatalf("read ics: %v", err)
+	}
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "UID:01JNA0000000000000000000T1-3f7608e04ab97bdb@nitid\r\n", "DTSTART;VALUE=DATE:20260309\r\n", "SUMMARY:Daily 2026-03-06\r\n"} {
+		if !strings.Contains(string(ics), want) {
+			t.Fatalf("ics missing %q:\n%s", want, ics)
+		}
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"agenda", "--days", "-1"}, ""))
+	mustFail(t, runCLI(t, dir, []string{"agenda", "--date", "tomorrow-ish"}, ""))
+}
+
+func TestCLI_Timezone(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+	record := `{"frontmatter":{"id":"01JNA0000000000000000000Z1","title":"Late night idea","created_at":"2026-03-02T05:00:00Z","updated_at":"2026-03-02T05:00:00Z"},"body":"idea"}`
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, record))
+
+	r := runCLI(t, dir, []string{"ls", "--created-after", "2026-03-02", "--format", "json"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Late night idea") {
+		t.Fatalf("expected UTC date filter to include note: %s", r.stdout)
+	}
+
+	configPath := filepath.Join(dir, ".nitid", "config.toml")
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	config = []byte(strings.Replace(string(config), "[vault]\n", "[vault]\ntimezone = \"America/Los_Angeles\"\n", 1))
+	if err := os.WriteFile(configPath, config, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"show", "01JNA000"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Created: 2026-03-01T21:00:00-08:00") {
+		t.Fatalf("show should render local time: %s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"show", "01JNA000", "--raw"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `created_at: "2026-03-02T05:00:00Z"`) {
+		t.Fatalf("frontmatter should stay UTC RFC3339: %s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"ls", "--created-after", "2026-03-02"}, "")
+	mustOK(t, r)
+	if strings.Contains(r.stdout, "Late night idea") {
+		t.Fatalf("local date filter should exclude note: %s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"ls", "--template", `{{date "2006-01-02 15:04" .CreatedAt}}`}, "")
+	mustOK(t, r)
+	if strings.TrimSpace(r.stdout) != "2026-03-01 21:00" {
+		t.Fatalf("template dates should use local time: %s", r.stdout)
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"daily", "--date", "2026-03-02"}, ""))
+	r = runCLI(t, dir, []string{"ls", "--kind", "daily", "--format", "json"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `"created_at": "2026-03-02T09:00:00Z"`) || !strings.Contains(r.stdout, `"title": "Daily 2026-03-02"`) {
+		t.Fatalf("daily note should keep its calendar date: %s", r.stdout)
+	}
+
+	config = []byte(strings.Replace(string(config), "America/Los_Angeles", "Mars/Olympus", 1))
+	if err := os.WriteFile(configPath, config, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	r = runCLI(t, dir, []string{"capture", "No dates shown"}, "")
+	mustOK(t, r)
+	if r.stderr != "" {
+		t.Fatalf("capture should not load the time zone: %s", r.stderr)
+	}
+	r = runCLI(t, dir, []string{"show", "01JNA000"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stderr, `ntd: warning: invalid timezone "Mars/Olympus"`) || !strings.Contains(r.stdout, "Created: 2026-03-02T05:00:00Z") {
+		t.Fatalf("expected UTC fallback with a warning: %s %s", r.stdout, r.stderr)
+	}
+	r = runCLI(t, dir, []string{"ls", "--created-after", "2026-03-02", "--format", "json"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Late night idea") || r.stderr == "" {
+		t.Fatalf("expected a JSON warning on stderr")
+	}
+	var warning map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(r.stderr)), &warning); err != nil || warning["type"] != "error" {
+		t.Fatalf("expected JSON error line, got %q: %v", r.stderr, err)
+	}
+	mustOK(t, runCLI(t, dir, []string{"version"}, ""))
+}
+
+func TestCLI_NaturalLanguageDates(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	records := []string{
+		`{"frontmatter":{"id":"01JNA0000000000000000000N1","title":"Planning","created_at":"2026-03-04T10:00:00Z"},"body":"- [ ] Book venue due:next-monday\n- [ ] Send invites due:tomorrow\n- [ ] Confirm due:2026-W12"}`,
+	}
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, strings.Join(records, "\n")))
+
+	r := runCLI(t, dir, []string{"tasks", "--format", "json"}, "")
+	mustOK(t, r)
+	for _, want := range []string{`"due": "2026-03-09"`, `"due": "2026-03-05"`, `"due": "2026-03-16"`} {
+		if !strings.Contains(r.stdout, want) {
+			t.Fatalf("tasks json missing %s: %s", want, r.stdout)
+		}
+	}
+
+	r = runCLI(t, dir, []string{"weekly", "--date", "2026-W10"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "rollup 2026-03-02 to 2026-03-08") {
+		t.Fatalf("weekly ISO week date not resolved: %s", r.stdout)
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"daily", "--date", "yesterday"}, ""))
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	r = runCLI(t, dir, []string{"ls", "--kind", "daily", "--created-after", "-2d"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Daily "+yesterday) {
+		t.Fatalf("expected daily for %s: %s", yesterday, r.stdout)
+	}
+	r = runCLI(t, dir, []string{"ls", "--kind", "daily", "--created-after", "today"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "no notes found") {
+		t.Fatalf("expected no dailies created today: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"daily", "--date", "fortnight"}, "")
+	mustFail(t, r)
+	if !strings.Contains(r.stderr, "daily --date: invalid date \"fortnight\"") {
+		t.Fatalf("unexpected error: %s", r.stderr)
+	}
+}
+
+func TestCLI_Log(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	records := []string{
+		`{"frontmatter":{"id":"01JNA0000000000000000000G1","title":"Retry design","created_at":"2026-03-02T10:00:00Z","updated_at":"2026-03-03T16:30:00Z","domain":"engineering"},"body":"design"}`,
+		`{"frontmatter":{"id":"01JNB0000000000000000000G2","title":"Vendor call","created_at":"2026-03-03T08:15:00Z","domain":"ops"},"body":"call"}`,
+		`{"frontmatter":{"id":"01JNC0000000000000000000G3","title":"Scratch","created_at":"2026-03-03T09:00:00Z"},"body":"tmp"}`,
+	}
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, strings.Join(records, "\n")))
+
+	r := runCLI(t, dir, []string{"log", "--since", "2026-03-01"}, "")
+	mustOK(t, r)
+	want := strings.Join([]string{
+		"2026-03-03 (Tuesday)",
+		"  16:30  updated   01JNA0000000  Retry design (engineering)",
+		"  09:00  created   01JNC0000000  Scratch",
+		"  08:15  created   01JNB0000000  Vendor call (ops)",
+		"",
+		"2026-03-02 (Monday)",
+		"  10:00  created   01JNA0000000  Retry design (engineering)",
+	}, "\n") + "\n"
+	if r.stdout != want {
+		t.Fatalf("log output unexpected:\n%s\nwant:\n%s", r.stdout, want)
+	}
+
+	mustOK(t, runCLI(t, dir, []string{"move", "01JNB000", "--domain", "engineering"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"archive", "01JNA000"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"delete", "01JNC000", "--yes"}, ""))
+
+	r = runCLI(t, dir, []string{"log", "--compact"}, "")
+	mustOK(t, r)
+	for _, want := range []string{
+		"moved     01JNB0000000  Vendor call (ops -> engineering)",
+		"archived  01JNA0000000  Retry design (engineering)",
+		"deleted   01JNC0000000  Scratch",
+	} {
+		if !strings.Contains(r.stdout, want) {
+			t.Fatalf("compact log missing %q:\n%s", want, r.stdout)
+		}
+	}
+	if strings.Contains(r.stdout, "updated") || strings.Contains(r.stdout, "2026-03-02") {
+		t.Fatalf("compact log should only include journaled recent events:\n%s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"log", "--since", "2026-03-01", "--domain", "ops", "--format", "ndjson"}, "")
+	mustOK(t, r)
+	lines := strings.Split(strings.TrimSpace(r.stdout), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"type":"log_event"`) || !strings.Contains(lines[0], `"action":"moved"`) || !strings.Contains(lines[0], `"from_domain":"ops"`) || !strings.Contains(lines[0], `"source":"journal"`) {
+		t.Fatalf("unexpected ndjson log: %s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"log", "--since", "2026-03-01", "--domain", "ops", "--format", "{{.Action}} {{.Title}} {{.FromDomain}}->{{.Domain}}"}, "")
+	mustOK(t, r)
+	if strings.TrimSpace(r.stdout) != "moved Vendor call ops->engineering" {
+		t.Fatalf("unexpected templated log: %q", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"--format", "json", "log", "--since", "2026-03-01", "--domain", "ops"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, `"command": "log"`) {
+		t.Fatalf("expected global json format before the command to apply: %s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"log", "--format", "yaml"}, "")
+	mustFail(t, r)
+	if !strings.Contains(r.stderr, `invalid log format "yaml"`) {
+		t.Fatalf("unexpected log format error: %s", r.stderr)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"log", "--since", "whenever"}, ""))
+	mustOK(t, runCLI(t, dir, []string{"validate"}, ""))
+}
+
+func TestCLI_Stats(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	records := []string{
+		`{"frontmatter":{"id":"01JNA0000000000000000000S1","title":"Retry design","created_at":"2026-03-02T10:00:00Z","updated_at":"2026-03-05T10:00:00Z","domain":"engineering","tags":["go","retries"]},"body":"A longer body about retries, backoff, and jitter in Go services."}`,
+		`{"frontmatter":{"id":"01JNB0000000000000000000S2","title":"Goroutine leak","created_at":"2026-03-03T10:00:00Z","domain":"engineering","tags":["go","retries","debugging"]},"body":"leak"}`,
+		`{"frontmatter":{"id":"01JNC0000000000000000000S3","title":"Read later","created_at":"2026-03-04T10:00:00Z"},"body":"link"}`,
+	}
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, strings.Join(records, "\n")))
+	mustOK(t, runCLI(t, dir, []string{"capture", "Fresh thought"}, ""))
+
+	r := runCLI(t, dir, []string{"stats", "--top", "2"}, "")
+	mustOK(t, r)
+	for _, want := range []string{
+		"notes: 4",
+		"by status:  active 2, inbox 2",
+		"by domain:  - 2, engineering 2",
+		"top tags:   go 2, retries 2, +1 more",
+		"created per week (",
+		"inbox: 2 notes, age p50 ",
+		"largest notes:\n  01JNA0000000",
+		"never updated since creation: 3\n  01JNB0000000  2026-03-03  Goroutine leak",
+		"tags used together:\n  go + retries  2\n  debugging + go  1",
+	} {
+		if !strings.Contains(r.stdout, want) {
+			t.Fatalf("stats output missing %q:\n%s", want, r.stdout)
+		}
+	}
+
+	r = runCLI(t, dir, []string{"stats", "--weeks", "4", "--domain", "engineering", "--format", "json"}, "")
+	mustOK(t, r)
+	var out struct {
+		Stats struct {
+			Total  int `json:"total"`
+			Weekly []struct {
+				Week  string `json:"week"`
+				Count int    `json:"count"`
+			} `json:"weekly"`
+			Inbox struct {
+				Count int `json:"count"`
+			} `json:"inbox"`
+			NeverUpdated struct {
+				Count int `json:"count"`
+			} `json:"never_updated"`
+			TagPairs []struct {
+				Tags  []string `json:"tags"`
+				Count int      `json:"count"`
+			} `json:"tag_pairs"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &out); err != nil {
+		t.Fatalf("decode: %v %s", err, r.stdout)
+	}
+	if out.Stats.Total != 2 || len(out.Stats.Weekly) != 4 || out.Stats.Inbox.Count != 0 || out.Stats.NeverUpdated.Count != 1 || len(out.Stats.TagPairs) != 3 || out.Stats.TagPairs[0].Count != 2 {
+		t.Fatalf("unexpected stats json: %+v", out.Stats)
+	}
+
+	mustFail(t, runCLI(t, dir, []string{"stats", "--weeks", "0"}, ""))
+}
+
+func TestCLI_Triage(t *testing.T) {
+	dir := t.TempDir()
+	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
+
+	records := []string{
+		`{"frontmatter":{"id":"01JNA0000000000000000000T1","title":"Retry backoff","created_at":"2026-03-01T10:00:00Z"},"body":"exponential backoff with jitter"}`,
+		`{"frontmatter":{"id":"01JNB0000000000000000000T2","title":"Read later","created_at":"2026-03-02T10:00:00Z"},"body":"link"}`,
+		`{"frontmatter":{"id":"01JNC0000000000000000000T3","title":"Old idea","created_at":"2026-03-03T10:00:00Z"},"body":"stale"}`,
+		`{"frontmatter":{"id":"01JND0000000000000000000T4","title":"Typo note","created_at":"2026-03-04T10:00:00Z"},"body":"oops"}`,
+		`{"frontmatter":{"id":"01JNE0000000000000000000T5","title":"Filed","created_at":"2026-03-05T10:00:00Z","domain":"engineering"},"body":"done"}`,
+	}
+	mustOK(t, runCLI(t, dir, []string{"import", "ndjson"}, strings.Join(records, "\n")))
+
+	r := runCLI(t, dir, []string{"triage"}, "m\nBad Domain\nm\nengineering\nt\ngo, reading\ns\nq\n")
+	mustOK(t, r)
+	for _, want := range []string{
+		"[1/4] Retry backoff",
+		"exponential backoff with jitter",
+		`invalid domain "bad domain"`,
+		"moved -> notes/domains/engineering/",
+		"[2/4] Read later",
+		"tags: go,reading",
+		"[3/4] Old idea",
+		"triage paused: 1 moved, 1 tagged, 0 archived, 0 deleted, 1 skipped; 3 left in inbox",
+	} {
+		if !strings.Contains(r.stdout, want) {
+			t.Fatalf("triage output missing %q:\n%s", want, r.stdout)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".nitid", "triage.json")); err != nil {
+		t.Fatalf("expected saved triage progress: %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"triage"}, "a\nx\nn\nx\ny\n")
+	mustOK(t, r)
+	for _, want := range []string{
+		"resuming triage started",
+		"[1/2] Old idea",
+		"[2/2] Typo note",
+		"kept",
+		"triage complete: 1 moved, 1 tagged, 1 archived, 1 deleted, 1 skipped; 1 left in inbox",
+	} {
+		if !strings.Contains(r.stdout, want) {
+			t.Fatalf("resumed triage output missing %q:\n%s", want, r.stdout)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".nitid", "triage.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected triage progress to be cleared, got %v", err)
+	}
+
+	r = runCLI(t, dir, []string{"ls", "--status", "inbox", "--format", "json"}, "")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "Read later") || !strings.Contains(r.stdout, "reading") || strings.Contains(r.stdout, "Typo note") {
+		t.Fatalf("unexpected inbox after triage:\n%s", r.stdout)
+	}
+
+	r = runCLI(t, dir, []string{"triage"}, "q\n")
+	mustOK(t, r)
+	if !strings.Contains(r.stdout, "[1/1] Read later") {
+		t.Fatalf("expected a fresh triage to revisit skipped notes:\n%s", r.stdout)
+	}
+	r = runCLI(t, dir, []string{"triage", "--restart"}, "s\n")
+	mustOK(t, r)
+	if strings.Contains(r.stdout, "resuming") || !strings.Contains(r.stdout, "triage complete: 0 moved, 0 tagged, 0 archived, 0 deleted, 1 skipped; 1 left in inbox") {
+		t.Fatalf("unexpected restarted triage output:\n%s", r.stdout)
+	}
+	mustFail(t, runCLI(t, dir, []string{"triage", "--format", "json"}, ""))
+}
+
 func TestCLI_TemplatesNewDaily(t *testing.T) {
 	dir := t.TempDir()
 	mustOK(t, runCLI(t, dir, []string{"init", "."}, ""))
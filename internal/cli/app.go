@@ -16,12 +16,18 @@ func run() int {
 }
 
 func Run(args []string) int {
+	args, format, err := extractFormatFlag(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ntd: %v\n", err)
+		return exitError
+	}
+	outputFormat = format
+
 	if len(args) == 0 {
 		printUsage()
 		return exitOK
 	}
 
-	var err error
 	switch args[0] {
 	case "help", "-h", "--help":
 		printUsage()
@@ -29,6 +35,16 @@ func Run(args []string) int {
 	case "version":
 		fmt.Printf("ntd %s\n", appVersion)
 		return exitOK
+	}
+
+	if jsonOutput() && !jsonFormatCommands[args[0]] {
+		reportJSONError(fmt.Errorf("%s does not support --format %s", args[0], outputFormat))
+		return exitError
+	}
+
+	cachedLocation = nil
+
+	switch args[0] {
 	case "init":
 		err = runInit(args[1:])
 	case "capture":
@@ -37,12 +53,30 @@ func Run(args []string) int {
 		err = runNew(args[1:])
 	case "daily":
 		err = runDaily(args[1:])
+	case "weekly", "monthly", "quarterly":
+		err = runPeriodic(args[0], args[1:])
+	case "stats":
+		err = runStats(args[1:])
+	case "triage":
+		err = runTriage(args[1:])
+	case "log":
+		err = runLog(args[1:])
+	case "agenda":
+		err = runAgenda(args[1:])
+	case "tasks":
+		err = runTasks(args[1:])
 	case "templates":
 		err = runTemplates(args[1:])
 	case "ls":
 		err = runList(args[1:])
 	case "find":
 		err = runFind(args[1:])
+	case "export":
+		err = runExport(args[1:])
+	case "import":
+		err = runImport(args[1:])
+	case "backup":
+		err = runBackup(args[1:])
 	case "move":
 		err = runMove(args[1:])
 	case "tag":
@@ -72,7 +106,11 @@ func Run(args []string) int {
 	}
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "ntd: %v\n", err)
+		if jsonOutput() {
+			reportJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "ntd: %v\n", err)
+		}
 		return exitError
 	}
 
@@ -85,13 +123,32 @@ func printUsage() {
 	fmt.Println("Usage:")
 	fmt.Println("  ntd version")
 	fmt.Println("  ntd init [path]")
-	fmt.Println("  ntd capture [text] [--title \"...\"] [--domain <id>] [--tags t1,t2] [--kind note|adr|snippet|daily]")
-	fmt.Println("  ntd new <template> [text] [--title \"...\"] [--domain <id>] [--tags t1,t2]")
-	fmt.Println("  ntd daily [--date YYYY-MM-DD] [--edit]")
+	fmt.Println("  ntd capture [text] [--title \"...\"] [--domain <id>] [--tags t1,t2] [--kind note|adr|snippet|daily|weekly|monthly|quarterly]")
+	fmt.Println("  ntd new <template> [text] [--title \"...\"] [--domain <id>] [--tags t1,t2] [--var key=value]")
+	fmt.Println("  ntd daily [--date <date>] [--edit] [--carry-over copy|move|off]")
+	fmt.Println("  ntd weekly|monthly|quarterly [--date <date>] [--edit]")
 	fmt.Println("  ntd templates")
 	fmt.Println("  ntd templates show <name>")
-	fmt.Println("  ntd ls [--domain <id>] [--tag <tag>] [--status inbox|active|archived] [--kind note|adr|snippet|daily] [--sort updated|created|title|id] [--asc]")
-	fmt.Println("  ntd find <query> [--domain <id>] [--tag <tag>] [--status inbox|active|archived] [--kind note|adr|snippet|daily] [--limit N]")
+	fmt.Println("  ntd templates new <name> [--from <template>] [--edit]")
+	fmt.Println("  ntd tasks [--open|--done] [--owner <name>] [--priority high|medium|low] [filters]")
+	fmt.Println("  ntd tasks done <id|@ref>:<line>")
+	fmt.Println("  ntd agenda [--days N] [--date <date>] [--ics <file>]")
+	fmt.Println("  ntd log [--since 7d] [--domain <id>] [--compact] [--format text|json|ndjson|<template>]")
+	fmt.Println("  ntd stats [--weeks N] [--top N] [filters]")
+	fmt.Println("  ntd triage [--restart]")
+	fmt.Println("  ntd ls [filters] [--sort updated|created|title|id] [--asc] [--long] [--body] [--template <tmpl|name>] [--template-file <path>]")
+	fmt.Println("  ntd find <query> [filters] [--fuzzy] [--context N] [--limit N] [--template <tmpl|name>] [--template-file <path>]")
+	fmt.Println("  ntd find --regex <pattern> [--in body|title|tags] [--case-sensitive] [--context N] [filters] [--limit N]")
+	fmt.Println("  ntd export csv|tsv [filters] [--columns id,title,...] [--separator ';'] [--out <file>] [--no-header]")
+	fmt.Println("  ntd export ndjson [filters] [--out <file>]")
+	fmt.Println("  ntd export html <outdir> [filters]")
+	fmt.Println("  ntd import markdown <dir> [--domain <id>] [--dry-run]")
+	fmt.Println("  ntd import obsidian <vault-dir> [--domain <id>] [--daily-folder <dir>] [--nested-tags flatten|leaf|parent|all] [--dry-run]")
+	fmt.Println("  ntd import enex <file.enex>... [--domain <id>] [--dry-run]")
+	fmt.Println("  ntd import ndjson [file|-] [--on-conflict skip|overwrite|newer-wins] [--dry-run]")
+	fmt.Println("  ntd backup [--out <file.tar.gz>]")
+	fmt.Println("  ntd backup verify <file>")
+	fmt.Println("  ntd backup restore <file> <dir>")
 	fmt.Println("  ntd move <id|@ref> --domain <id>")
 	fmt.Println("  ntd tag <id|@ref> add|rm <tag>")
 	fmt.Println("  ntd archive <id|@ref>")
@@ -104,16 +161,49 @@ func printUsage() {
 	fmt.Println("  ntd tui")
 	fmt.Println("  ntd completion bash")
 	fmt.Println()
+	fmt.Println("Global flags:")
+	fmt.Println("  --format text|json|ndjson   machine-readable output for ls, find, show, tasks, agenda, log, stats, validate, doctor, import, backup, templates")
+	fmt.Println()
+	fmt.Println("Filters (ls, find, tasks, stats, export):")
+	fmt.Println("  --domain <id> | --not-domain <id>")
+	fmt.Println("  --status inbox|active|archived | --not-status <status>")
+	fmt.Println("  --kind note|adr|snippet|daily|weekly|monthly|quarterly | --not-kind <kind>")
+	fmt.Println("  --tag <tag> (repeatable, all must match) | --any-tag <tag> (repeatable) | --not-tag <tag> (repeatable)")
+	fmt.Println("  --created-after|--created-before|--updated-after|--updated-before <date|RFC3339|7d|2w>")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  ntd version")
 	fmt.Println("  ntd init .")
 	fmt.Println("  ntd capture \"Investigate goroutine leak in worker pool\"")
 	fmt.Println("  ntd new adr --title \"Use ULID for note IDs\"")
+	fmt.Println("  ntd new meeting --var attendees=\"Ana, Bo\"")
 	fmt.Println("  ntd daily --edit")
+	fmt.Println("  ntd weekly --edit")
+	fmt.Println("  ntd daily --date \"last friday\"")
 	fmt.Println("  ntd templates")
+	fmt.Println("  ntd templates new standup --from meeting --edit")
+	fmt.Println("  ntd tasks --open --owner ana")
+	fmt.Println("  ntd tasks done 01JNA0ZX:12")
+	fmt.Println("  ntd agenda --days 14 --ics ~/calendars/nitid.ics")
+	fmt.Println("  ntd log --since 2w --domain engineering --compact")
+	fmt.Println("  ntd stats --weeks 26 --format json")
+	fmt.Println("  ntd triage")
 	fmt.Println("  ntd ls --status inbox --sort updated")
 	fmt.Println("  ntd find worker --limit 10")
+	fmt.Println("  ntd find --fuzzy goroutne")
+	fmt.Println("  ntd find --regex 'panic: .*nil' --in body")
+	fmt.Println("  ntd ls --status inbox --created-before 14d")
+	fmt.Println("  ntd ls --tag go --tag concurrency --not-tag draft")
 	fmt.Println("  ntd ls --long")
+	fmt.Println("  ntd ls --format json --body")
+	fmt.Println("  ntd ls --domain engineering --template '- [[{{.ID}}]] {{.Title}} ({{.Domain}})'")
+	fmt.Println("  ntd export csv --kind adr --columns id,title,status,updated --out adrs.csv")
+	fmt.Println("  ntd export html ./site")
+	fmt.Println("  ntd import markdown ~/old-notes --dry-run")
+	fmt.Println("  ntd import obsidian ~/ObsidianVault --dry-run")
+	fmt.Println("  ntd import enex Work.enex")
+	fmt.Println("  ntd backup --out /mnt/usb/notes.tar.gz && ntd backup verify /mnt/usb/notes.tar.gz")
+	fmt.Println("  ntd export ndjson | jq -c '.frontmatter.tags += [\"reviewed\"]' | ntd import ndjson --on-conflict overwrite")
 	fmt.Println("  ntd move @1 --domain engineering")
 	fmt.Println("  ntd tag @1 add concurrency")
 	fmt.Println("  ntd archive @1")
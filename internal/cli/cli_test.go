@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -96,3 +98,145 @@ func TestCollectNotePaths(t *testing.T) {
 		t.Fatalf("expected 1 path, got %d", report.Total)
 	}
 }
+
+func TestResolveFilterTime(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "", want: time.Time{}},
+		{input: "7d", want: now.AddDate(0, 0, -7)},
+		{input: "2w", want: now.AddDate(0, 0, -14)},
+		{input: "2026-03-01", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{input: "2026-03-01T08:30:00Z", want: time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)},
+		{input: "yesterday", want: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{input: "last friday", want: time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{input: "-3d", want: time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{input: "2026-W10", want: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveFilterTime("--created-after", tt.input, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for %q", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("resolve %q: %v", tt.input, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Fatalf("resolve %q: got=%s want=%s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestResolveFilterTimeUsesDisplayLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	old := cachedLocation
+	cachedLocation = loc
+	defer func() { cachedLocation = old }()
+
+	got, err := resolveFilterTime("--created-after", "2026-03-01", time.Now())
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("got=%s want=%s", got, want)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		counts []int
+		want   string
+	}{
+		{counts: []int{0, 0, 0}, want: "▁▁▁"},
+		{counts: []int{0, 1, 7, 3}, want: "▁▂█▄"},
+		{counts: []int{2, 2}, want: "██"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.counts); got != tt.want {
+			t.Fatalf("sparkline(%v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteWord(t *testing.T) {
+	candidates := []string{"engineering", "engine-room", "ops"}
+	tests := []struct {
+		prefix  string
+		want    string
+		matches int
+	}{
+		{prefix: "o", want: "ops", matches: 1},
+		{prefix: "eng", want: "engine", matches: 2},
+		{prefix: "engineer", want: "engineering", matches: 1},
+		{prefix: "x", want: "x", matches: 0},
+	}
+	for _, tt := range tests {
+		got, matches := completeWord(tt.prefix, candidates)
+		if got != tt.want || len(matches) != tt.matches {
+			t.Fatalf("completeWord(%q) = %q %v, want %q with %d matches", tt.prefix, got, matches, tt.want, tt.matches)
+		}
+	}
+}
+
+func TestExtractFormatFlag(t *testing.T) {
+	tests := []struct {
+		args   []string
+		rest   []string
+		format string
+	}{
+		{args: []string{"ls", "--format", "json", "--long"}, rest: []string{"ls", "--long"}, format: formatJSON},
+		{args: []string{"--format=ndjson", "find", "x"}, rest: []string{"find", "x"}, format: formatNDJSON},
+		{args: []string{"log", "--format", "{{.Title}}"}, rest: []string{"log", "--format", "{{.Title}}"}, format: formatText},
+		{args: []string{"--format", "json", "log", "--compact"}, rest: []string{"log", "--compact"}, format: formatJSON},
+	}
+	for _, tt := range tests {
+		rest, format, err := extractFormatFlag(tt.args)
+		if err != nil {
+			t.Fatalf("extract %v: %v", tt.args, err)
+		}
+		if format != tt.format || strings.Join(rest, " ") != strings.Join(tt.rest, " ") {
+			t.Fatalf("extract %v: got %v %q, want %v %q", tt.args, rest, format, tt.rest, tt.format)
+		}
+	}
+}
+
+func TestTruncatePositions(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		positions []int
+		max       int
+		want      string
+		wantPos   []int
+	}{
+		{name: "untouched", value: "Retry backoff", positions: []int{0, 1, 2}, max: 20, want: "Retry backoff", wantPos: []int{0, 1, 2}},
+		{name: "leading space", value: "  Retry backoff", positions: []int{0, 2, 3}, max: 20, want: "Retry backoff", wantPos: []int{0, 1}},
+		{name: "ellipsis", value: "Retry backoff with jitter", positions: []int{6, 7, 10, 11, 12}, max: 13, want: "Retry back...", wantPos: []int{6, 7}},
+		{name: "runes", value: "Café résumé notes", positions: []int{3, 5, 6}, max: 9, want: "Café r...", wantPos: []int{3, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotPos := truncatePositions(tt.value, tt.positions, tt.max)
+			if got != tt.want || fmt.Sprint(gotPos) != fmt.Sprint(tt.wantPos) {
+				t.Fatalf("truncatePositions(%q) = %q %v, want %q %v", tt.value, got, gotPos, tt.want, tt.wantPos)
+			}
+		})
+	}
+
+	body, positions := trimPositions("\n\n  alpha\nbeta  \n", []int{4, 5, 12, 14})
+	if body != "alpha\nbeta" || fmt.Sprint(positions) != "[0 1 8]" {
+		t.Fatalf("trimPositions = %q %v", body, positions)
+	}
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/core"
+)
+
+type agendaJSON struct {
+	Today    string     `json:"today"`
+	End      string     `json:"end"`
+	Overdue  []taskJSON `json:"overdue"`
+	DueToday []taskJSON `json:"due_today"`
+	Upcoming []taskJSON `json:"upcoming"`
+	Dailies  []noteJSON `json:"dailies"`
+}
+
+type agendaLine struct {
+	day  string
+	text string
+}
+
+func runAgenda(args []string) error {
+	fs := flag.NewFlagSet("agenda", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	days := fs.Int("days", 7, "number of upcoming days to include")
+	dateArg := fs.String("date", "", "agenda start date (default today)")
+	icsPath := fs.String("ics", "", "write the agenda as an iCalendar file ('-' for stdout)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("agenda usage: ntd agenda [--days N] [--date <date>] [--ics <file>]")
+	}
+	if *days < 0 {
+		return errors.New("agenda --days must not be negative")
+	}
+	today, err := resolveDayFlag("agenda", *dateArg)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	agenda, err := svc.Agenda(today, *days)
+	if err != nil {
+		return err
+	}
+	prefixes, err := taskRefPrefixes(svc)
+	if err != nil {
+		return err
+	}
+
+	if *icsPath != "" {
+		calendar := agenda.ICS(time.Now().UTC())
+		if *icsPath == "-" {
+			_, err := io.WriteString(os.Stdout, calendar)
+			return err
+		}
+		if err := os.WriteFile(*icsPath, []byte(calendar), 0o644); err != nil {
+			return err
+		}
+		events := len(agenda.Overdue) + len(agenda.DueToday) + len(agenda.Upcoming) + len(agenda.Dailies)
+		if jsonOutput() {
+			return emitRecord("agenda", "agenda_ics", "ics", map[string]any{"path": *icsPath, "events": events})
+		}
+		fmt.Printf("wrote %d events to %s\n", events, *icsPath)
+		return nil
+	}
+
+	if jsonOutput() {
+		out := agendaJSON{
+			Today:    agenda.Today.Format("2006-01-02"),
+			End:      agenda.End.Format("2006-01-02"),
+			Overdue:  agendaTasksJSON(agenda.Overdue, prefixes),
+			DueToday: agendaTasksJSON(agenda.DueToday, prefixes),
+			Upcoming: agendaTasksJSON(agenda.Upcoming, prefixes),
+			Dailies:  make([]noteJSON, 0, len(agenda.Dailies)),
+		}
+		for _, item := range agenda.Dailies {
+			out.Dailies = append(out.Dailies, toNoteJSON(item, "", false))
+		}
+		return emitRecord("agenda", "agenda", "agenda", out)
+	}
+
+	todayKey := agenda.Today.Format("2006-01-02")
+	var dueToday, upcoming []agendaLine
+	for _, item := range agenda.Dailies {
+		day := item.Note.CreatedAt.Format("2006-01-02")
+		line := agendaLine{day: day, text: fmt.Sprintf("%s  %-16s  %s", day, shortRef(prefixes, item.Note.ID), item.Note.Title)}
+		if day == todayKey {
+			dueToday = append(dueToday, line)
+		} else {
+			upcoming = append(upcoming, line)
+		}
+	}
+	dueToday = append(dueToday, agendaTaskLines(agenda.DueToday, prefixes)...)
+	upcoming = append(upcoming, agendaTaskLines(agenda.Upcoming, prefixes)...)
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].day < upcoming[j].day })
+
+	printAgendaGroup(fmt.Sprintf("Overdue (%d)", len(agenda.Overdue)), agendaTaskLines(agenda.Overdue, prefixes))
+	printAgendaGroup(fmt.Sprintf("Today %s (%d)", todayKey, len(dueToday)), dueToday)
+	printAgendaGroup(fmt.Sprintf("Upcoming to %s (%d)", agenda.End.Format("2006-01-02"), len(upcoming)), upcoming)
+	return nil
+}
+
+func agendaTasksJSON(tasks []core.Task, prefixes map[string]string) []taskJSON {
+	items := make([]taskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		items = append(items, toTaskJSON(task, task.Ref(prefixes[task.Note.Note.ID])))
+	}
+	return items
+}
+
+func agendaTaskLines(tasks []core.Task, prefixes map[string]string) []agendaLine {
+	lines := make([]agendaLine, 0, len(tasks))
+	for _, task := range tasks {
+		day := task.Due.Format("2006-01-02")
+		context := task.Note.Note.Title
+		if task.Heading != "" {
+			context += " > " + task.Heading
+		}
+		lines = append(lines, agendaLine{day: day, text: fmt.Sprintf("%s  %-16s  [ ] %s  (%s)", day, task.Ref(prefixes[task.Note.Note.ID]), task.Text, truncate(context, 60))})
+	}
+	return lines
+}
+
+func printAgendaGroup(heading string, lines []agendaLine) {
+	fmt.Println(heading)
+	if len(lines) == 0 {
+		fmt.Println("  nothing")
+	}
+	for _, line := range lines {
+		fmt.Println("  " + strings.TrimRight(line.text, " "))
+	}
+}
+
+func shortRef(prefixes map[string]string, id string) string {
+	if prefix := prefixes[id]; prefix != "" {
+		return prefix
+	}
+	return shortID(id)
+}
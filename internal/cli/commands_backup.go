@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"nitid/internal/core"
+)
+
+const backupWarnAge = 7 * 24 * time.Hour
+
+type backupJSON struct {
+	Path          string   `json:"path"`
+	SchemaVersion int      `json:"schema_version"`
+	CreatedAt     string   `json:"created_at"`
+	Files         int      `json:"files"`
+	Bytes         int64    `json:"bytes"`
+	Problems      []string `json:"problems,omitempty"`
+	Passed        *bool    `json:"passed,omitempty"`
+	RestoredTo    string   `json:"restored_to,omitempty"`
+}
+
+func runBackup(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "verify":
+			return runBackupVerify(args[1:])
+		case "restore":
+			return runBackupRestore(args[1:])
+		}
+	}
+
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	outPath := fs.String("out", "", "archive path (default .nitid/backups/nitid-backup-<time>.tar.gz)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("backup usage: ntd backup [--out <file.tar.gz>] | backup verify <file> | backup restore <file> <dir>")
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	out := strings.TrimSpace(*outPath)
+	if out == "" {
+		out = core.DefaultBackupPath(svc.Root(), now)
+	}
+
+	result, err := svc.Backup(out, now)
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return emitRecord("backup", "backup", "backup", toBackupJSON(result.Path, result.Manifest))
+	}
+	fmt.Printf("backed up %d files (%s) to %s\n", len(result.Manifest.Files), formatBytes(manifestBytes(result.Manifest)), result.Path)
+	return nil
+}
+
+func runBackupVerify(args []string) error {
+	if len(args) != 1 {
+		return errors.New("backup verify usage: ntd backup verify <file>")
+	}
+
+	check, err := core.VerifyBackup(args[0])
+	if err != nil {
+		return err
+	}
+	passed := len(check.Problems) == 0
+	if jsonOutput() {
+		out := toBackupJSON(check.Path, check.Manifest)
+		out.Problems = nonNilStrings(check.Problems)
+		out.Passed = &passed
+		if err := emitRecord("backup verify", "backup_check", "backup", out); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range check.Problems {
+			fmt.Printf("[fail] %s\n", problem)
+		}
+		fmt.Printf("backup %s: %d files, schema version %d, created %s\n", check.Path, len(check.Manifest.Files), check.Manifest.SchemaVersion, check.Manifest.CreatedAt.In(displayLocation()).Format(time.RFC3339))
+	}
+
+	if !passed {
+		return fmt.Errorf("backup verification failed: %d problems", len(check.Problems))
+	}
+	if !jsonOutput() {
+		fmt.Println("backup verified: all checksums match")
+	}
+	return nil
+}
+
+func runBackupRestore(args []string) error {
+	if len(args) != 2 {
+		return errors.New("backup restore usage: ntd backup restore <file> <dir>")
+	}
+
+	result, err := core.RestoreBackup(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		out := toBackupJSON(args[0], result.Manifest)
+		out.RestoredTo = result.Dir
+		return emitRecord("backup restore", "backup_restore", "backup", out)
+	}
+	fmt.Printf("restored %d files to %s\n", len(result.Manifest.Files), result.Dir)
+	return nil
+}
+
+func backupDoctorCheck(svc *core.Service, now time.Time) doctorCheck {
+	record, ok, err := svc.LastBackup()
+	switch {
+	case err != nil:
+		return doctorCheck{Name: "backup", Status: "warn", Message: fmt.Sprintf("cannot read last backup: %v", err)}
+	case !ok:
+		return doctorCheck{Name: "backup", Status: "ok", Message: "no backup yet: run ntd backup"}
+	}
+
+	message := fmt.Sprintf("last backup: %s (%s)", relativeTime(record.CreatedAt, now), record.Path)
+	if now.Sub(record.CreatedAt) > backupWarnAge {
+		return doctorCheck{Name: "backup", Status: "warn", Message: message}
+	}
+	return doctorCheck{Name: "backup", Status: "ok", Message: message}
+}
+
+func toBackupJSON(path string, manifest core.BackupManifest) backupJSON {
+	return backupJSON{
+		Path:          path,
+		SchemaVersion: manifest.SchemaVersion,
+		CreatedAt:     manifest.CreatedAt.Format(time.RFC3339),
+		Files:         len(manifest.Files),
+		Bytes:         manifestBytes(manifest),
+	}
+}
+
+func manifestBytes(manifest core.BackupManifest) int64 {
+	var total int64
+	for _, file := range manifest.Files {
+		total += file.Size
+	}
+	return total
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
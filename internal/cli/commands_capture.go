@@ -118,15 +118,5 @@ func resolveBody(args []string) (string, error) {
 }
 
 func inferTitle(body string) string {
-	body = strings.TrimSpace(body)
-	if body == "" {
-		return "Untitled"
-	}
-
-	line := strings.Split(body, "\n")[0]
-	line = strings.TrimSpace(line)
-	if len(line) <= 72 {
-		return line
-	}
-	return strings.TrimSpace(line[:72])
+	return core.InferTitle(body)
 }
@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nitid/internal/core"
+)
+
+var defaultExportColumns = []string{"id", "title", "domain", "tags", "status", "kind", "created", "updated", "path"}
+
+var builtinExportColumns = []string{"id", "title", "domain", "tags", "links", "status", "kind", "created", "updated", "path", "body"}
+
+func runExport(args []string) error {
+	if len(args) == 0 {
+		return errors.New("export usage: ntd export csv|tsv|ndjson|html [flags]")
+	}
+
+	switch args[0] {
+	case "csv":
+		return runExportTable(args[1:], ',')
+	case "tsv":
+		return runExportTable(args[1:], '\t')
+	case "ndjson":
+		return runExportNDJSON(args[1:])
+	case "html":
+		return runExportHTML(args[1:])
+	default:
+		return fmt.Errorf("unknown export format %q", args[0])
+	}
+}
+
+func runExportTable(args []string, comma rune) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var filters noteFilterFlags
+	filters.register(fs)
+	columnsArg := fs.String("columns", strings.Join(defaultExportColumns, ","), "comma-separated columns")
+	separator := fs.String("separator", ";", "separator for multi-valued fields")
+	outPath := fs.String("out", "", "write to file instead of stdout")
+	noHeader := fs.Bool("no-header", false, "omit the header row")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("export does not accept positional arguments")
+	}
+
+	columns := make([]string, 0)
+	for _, piece := range strings.Split(*columnsArg, ",") {
+		piece = strings.TrimSpace(piece)
+		if piece != "" {
+			columns = append(columns, piece)
+		}
+	}
+	if len(columns) == 0 {
+		return errors.New("--columns requires at least one column")
+	}
+
+	filter, err := filters.build(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	notes, err := svc.List(filter, "updated", false)
+	if err != nil {
+		return err
+	}
+	if err := checkExportColumns(columns, notes); err != nil {
+		all, listErr := svc.List(NoteFilter{}, "updated", false)
+		if listErr != nil {
+			return listErr
+		}
+		if err := checkExportColumns(columns, all); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if strings.TrimSpace(*outPath) != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	w := csv.NewWriter(out)
+	w.Comma = comma
+	if !*noHeader {
+		if err := w.Write(columns); err != nil {
+			return err
+		}
+	}
+	for _, item := range notes {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, exportColumnValue(item, column, *separator))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	if *outPath != "" {
+		fmt.Fprintf(os.Stderr, "exported %d notes to %s\n", len(notes), *outPath)
+	}
+	return nil
+}
+
+func runExportNDJSON(args []string) error {
+	fs := flag.NewFlagSet("export ndjson", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var filters noteFilterFlags
+	filters.register(fs)
+	outPath := fs.String("out", "", "write to file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("export does not accept positional arguments")
+	}
+
+	filter, err := filters.build(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	notes, err := svc.List(filter, "created", true)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if strings.TrimSpace(*outPath) != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	for _, item := range notes {
+		if err := writeJSONLine(out, core.NewNoteRecord(item)); err != nil {
+			return err
+		}
+	}
+
+	if *outPath != "" {
+		fmt.Fprintf(os.Stderr, "exported %d notes to %s\n", len(notes), *outPath)
+	}
+	return nil
+}
+
+func runExportHTML(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("export html usage: ntd export html <outdir> [filters]")
+	}
+	outDir := args[0]
+
+	fs := flag.NewFlagSet("export html", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var filters noteFilterFlags
+	filters.register(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("export html accepts one output directory")
+	}
+
+	filter, err := filters.build(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	result, err := svc.ExportSite(outDir, filter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("exported %d notes (%d pages, %d assets) to %s\n", result.Notes, result.Pages, result.Assets, result.OutDir)
+	fmt.Printf("open %s\n", filepath.Join(result.OutDir, "index.html"))
+	return nil
+}
+
+func checkExportColumns(columns []string, notes []NoteFile) error {
+	builtin := map[string]bool{}
+	for _, column := range builtinExportColumns {
+		builtin[column] = true
+	}
+	for _, column := range columns {
+		if builtin[strings.ToLower(column)] {
+			continue
+		}
+		found := false
+		for _, item := range notes {
+			if _, ok := item.Note.Extra[column]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown column %q: use %s, or a frontmatter key used in the vault", column, strings.Join(builtinExportColumns, ", "))
+		}
+	}
+	return nil
+}
+
+func exportColumnValue(item NoteFile, column, separator string) string {
+	note := item.Note
+	switch strings.ToLower(column) {
+	case "id":
+		return note.ID
+	case "title":
+		return note.Title
+	case "domain":
+		return note.Domain
+	case "tags":
+		return strings.Join(note.Tags, separator)
+	case "links":
+		return strings.Join(note.Links, separator)
+	case "status":
+		return note.Status
+	case "kind":
+		return note.Kind
+	case "created":
+		return note.CreatedAt.Format(time.RFC3339)
+	case "updated":
+		return note.UpdatedAt.Format(time.RFC3339)
+	case "path":
+		return item.RelPath
+	case "body":
+		return note.Body
+	}
+
+	value, ok := note.Extra[column]
+	if !ok {
+		return ""
+	}
+	return formatExtraValue(value, separator)
+}
+
+func formatExtraValue(value any, separator string) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case time.Time:
+		return typed.UTC().Format(time.RFC3339)
+	case []any:
+		parts := make([]string, 0, len(typed))
+		for _, part := range typed {
+			parts = append(parts, formatExtraValue(part, separator))
+		}
+		return strings.Join(parts, separator)
+	case map[string]any:
+		b, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Sprint(typed)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"nitid/internal/core"
+)
+
+type importItemJSON struct {
+	Source   string   `json:"source"`
+	ID       string   `json:"id,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	RelPath  string   `json:"rel_path,omitempty"`
+	Domain   string   `json:"domain,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Replaced bool     `json:"replaced,omitempty"`
+}
+
+type importAssetJSON struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+type importReportJSON struct {
+	Source   string            `json:"source"`
+	DryRun   bool              `json:"dry_run"`
+	Imported []importItemJSON  `json:"imported"`
+	Skipped  []importItemJSON  `json:"skipped"`
+	Assets   []importAssetJSON `json:"assets"`
+	Warnings []string          `json:"warnings"`
+}
+
+func runImport(args []string) error {
+	if len(args) == 0 {
+		return errors.New("import usage: ntd import markdown|obsidian|enex|ndjson <source> [flags]")
+	}
+
+	switch args[0] {
+	case "markdown":
+		return runImportMarkdown(args[1:])
+	case "obsidian":
+		return runImportObsidian(args[1:])
+	case "enex":
+		return runImportEnex(args[1:])
+	case "ndjson":
+		return runImportNDJSON(args[1:])
+	default:
+		return fmt.Errorf("unknown import format %q", args[0])
+	}
+}
+
+func runImportMarkdown(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("import markdown usage: ntd import markdown <dir> [--domain <id>] [--dry-run]")
+	}
+	source := args[0]
+
+	fs := flag.NewFlagSet("import markdown", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	domain := fs.String("domain", "", "domain for files at the top level")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("import markdown accepts one source directory")
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	report, err := svc.ImportMarkdown(source, core.ImportOptions{
+		DryRun: *dryRun,
+		Domain: strings.ToLower(strings.TrimSpace(*domain)),
+	})
+	if err != nil {
+		return err
+	}
+	return printImportReport(report)
+}
+
+func runImportObsidian(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("import obsidian usage: ntd import obsidian <vault-dir> [flags]")
+	}
+	source := args[0]
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	cfg, err := svc.Config()
+	if err != nil {
+		return err
+	}
+	opts := core.DefaultObsidianOptions(cfg)
+
+	fs := flag.NewFlagSet("import obsidian", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	domain := fs.String("domain", "", "domain for notes at the top level")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported")
+	var dailyFolders stringList
+	fs.Var(&dailyFolders, "daily-folder", "folder holding daily notes (repeatable)")
+	fs.StringVar(&opts.DailyFormat, "daily-format", opts.DailyFormat, "daily note file name format")
+	fs.StringVar(&opts.DomainFrom, "domain-from", opts.DomainFrom, "folder|property|none")
+	fs.StringVar(&opts.NestedTags, "nested-tags", opts.NestedTags, "flatten|leaf|parent|all")
+	fs.StringVar(&opts.TitleFrom, "title-from", opts.TitleFrom, "filename|heading")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("import obsidian accepts one vault directory")
+	}
+
+	opts.DryRun = *dryRun
+	opts.Domain = strings.ToLower(strings.TrimSpace(*domain))
+	if len(dailyFolders) > 0 {
+		opts.DailyFolders = dailyFolders
+	}
+	report, err := svc.ImportObsidian(source, opts)
+	if err != nil {
+		return err
+	}
+	return printImportReport(report)
+}
+
+func runImportEnex(args []string) error {
+	files := make([]string, 0)
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		files = append(files, args[0])
+		args = args[1:]
+	}
+	if len(files) == 0 {
+		return errors.New("import enex usage: ntd import enex <file.enex>... [--domain <id>] [--dry-run]")
+	}
+
+	fs := flag.NewFlagSet("import enex", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	domain := fs.String("domain", "", "domain for all imported notes (default: notebook name)")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("import enex: put .enex files before flags")
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	report, err := svc.ImportEnex(files, core.EnexOptions{
+		DryRun: *dryRun,
+		Domain: strings.ToLower(strings.TrimSpace(*domain)),
+	})
+	if err != nil {
+		return err
+	}
+	return printImportReport(report)
+}
+
+func runImportNDJSON(args []string) error {
+	source := "-"
+	if len(args) > 0 && (args[0] == "-" || !strings.HasPrefix(args[0], "-")) {
+		source = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("import ndjson", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	onConflict := fs.String("on-conflict", core.ConflictSkip, "skip|overwrite|newer-wins")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("import ndjson usage: ntd import ndjson [file|-] [--on-conflict skip|overwrite|newer-wins] [--dry-run]")
+	}
+	policy := strings.ToLower(strings.TrimSpace(*onConflict))
+	if !core.IsConflictPolicy(policy) {
+		return fmt.Errorf("invalid --on-conflict %q: use skip, overwrite, or newer-wins", *onConflict)
+	}
+
+	var in io.Reader = os.Stdin
+	label := "stdin"
+	if source != "-" {
+		file, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+		label = source
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	report, err := svc.ImportNDJSON(label, in, core.NDJSONOptions{DryRun: *dryRun, OnConflict: policy})
+	if err != nil {
+		return err
+	}
+	return printImportReport(report)
+}
+
+func printImportReport(report core.ImportReport) error {
+	if jsonOutput() {
+		return emitRecord("import", "import_report", "report", toImportReportJSON(report))
+	}
+
+	verb := "imported"
+	if report.DryRun {
+		verb = "would import"
+	}
+	for _, item := range report.Imported {
+		action := verb
+		if item.Replaced {
+			action = "replaced"
+			if report.DryRun {
+				action = "would replace"
+			}
+		}
+		fmt.Printf("%-12s %s <- %s\n", action, item.RelPath, item.Source)
+	}
+	for _, asset := range report.Assets {
+		fmt.Printf("%-12s %s <- %s\n", "asset", asset.Target, asset.Source)
+	}
+	for _, item := range report.Skipped {
+		fmt.Printf("%-12s %s (%s)\n", "skipped", item.Source, item.Reason)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Printf("%-12s %s\n", "warning", warning)
+	}
+
+	summary := fmt.Sprintf("%s %d notes, skipped %d, %d warnings", verb, len(report.Imported), len(report.Skipped), len(report.Warnings))
+	if len(report.Assets) > 0 {
+		summary = fmt.Sprintf("%s %d notes and %d assets, skipped %d, %d warnings", verb, len(report.Imported), len(report.Assets), len(report.Skipped), len(report.Warnings))
+	}
+	if report.DryRun {
+		summary += " (dry run, nothing written)"
+	}
+	fmt.Println(summary)
+	return nil
+}
+
+func toImportReportJSON(report core.ImportReport) importReportJSON {
+	convert := func(items []core.ImportItem) []importItemJSON {
+		out := make([]importItemJSON, 0, len(items))
+		for _, item := range items {
+			out = append(out, importItemJSON{
+				Source:   item.Source,
+				ID:       item.NoteID,
+				Title:    item.Title,
+				RelPath:  item.RelPath,
+				Domain:   item.Domain,
+				Tags:     item.Tags,
+				Reason:   item.Reason,
+				Replaced: item.Replaced,
+			})
+		}
+		return out
+	}
+	assets := make([]importAssetJSON, 0, len(report.Assets))
+	for _, asset := range report.Assets {
+		assets = append(assets, importAssetJSON{Source: asset.Source, Target: asset.Target})
+	}
+	return importReportJSON{
+		Source:   report.Source,
+		Assets:   assets,
+		DryRun:   report.DryRun,
+		Imported: convert(report.Imported),
+		Skipped:  convert(report.Skipped),
+		Warnings: nonNilStrings(report.Warnings),
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"nitid/internal/core"
+)
+
+type logEventJSON struct {
+	Time       string `json:"time"`
+	Action     string `json:"action"`
+	NoteID     string `json:"note_id"`
+	Title      string `json:"title"`
+	Domain     string `json:"domain"`
+	FromDomain string `json:"from_domain,omitempty"`
+	RelPath    string `json:"rel_path"`
+	Source     string `json:"source"`
+}
+
+func runLog(args []string) error {
+	fs := flag.NewFlagSet("log", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	since := fs.String("since", "7d", "show events on or after this date or duration")
+	domain := fs.String("domain", "", "only events for notes in this domain")
+	compact := fs.Bool("compact", false, "print one line per event without day headings")
+	format := fs.String("format", outputFormat, "text, json, ndjson, or a template for each event")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("log usage: ntd log [--since 7d] [--domain <id>] [--compact] [--format text|json|ndjson|<template>]")
+	}
+	var tmpl *template.Template
+	switch value := strings.TrimSpace(*format); strings.ToLower(value) {
+	case formatText, formatJSON, formatNDJSON:
+		outputFormat = strings.ToLower(value)
+	default:
+		if !strings.Contains(value, "{{") {
+			return fmt.Errorf("invalid log format %q: use text, json, ndjson, or a template", value)
+		}
+		parsed, err := template.New("log").Funcs(outputTemplateFuncs(time.Now().UTC())).Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		tmpl = parsed
+	}
+	domainID := strings.ToLower(strings.TrimSpace(*domain))
+	if domainID != "" && !domainIDPattern.MatchString(domainID) {
+		return fmt.Errorf("invalid domain %q: use lowercase kebab-case", domainID)
+	}
+	sinceTime, err := resolveFilterTime("--since", *since, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	events, err := svc.Log(sinceTime, domainID)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return emitLogEvents(events)
+	}
+	if tmpl != nil {
+		return renderLogTemplate(tmpl, events)
+	}
+
+	if len(events) == 0 {
+		fmt.Println("no activity found")
+		return nil
+	}
+
+	currentDay := ""
+	for _, event := range events {
+		local := event.Time.In(displayLocation())
+		if *compact {
+			fmt.Printf("%s  %-8s  %-12s  %s%s\n", local.Format("2006-01-02 15:04"), event.Action, shortID(event.NoteID), event.Title, logEventContext(event))
+			continue
+		}
+		if day := local.Format("2006-01-02"); day != currentDay {
+			if currentDay != "" {
+				fmt.Println()
+			}
+			fmt.Printf("%s (%s)\n", day, local.Format("Monday"))
+			currentDay = day
+		}
+		fmt.Printf("  %s  %-8s  %-12s  %s%s\n", local.Format("15:04"), event.Action, shortID(event.NoteID), event.Title, logEventContext(event))
+	}
+	return nil
+}
+
+func renderLogTemplate(tmpl *template.Template, events []core.LogEvent) error {
+	for _, event := range events {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, event); err != nil {
+			return fmt.Errorf("render template for %s: %w", event.NoteID, err)
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		fmt.Print(out)
+	}
+	return nil
+}
+
+func logEventContext(event core.LogEvent) string {
+	switch {
+	case event.Action == core.LogMoved:
+		return fmt.Sprintf(" (%s -> %s)", displayDomain(event.FromDomain), displayDomain(event.Domain))
+	case event.Domain != "":
+		return " (" + event.Domain + ")"
+	default:
+		return ""
+	}
+}
+
+func emitLogEvents(events []core.LogEvent) error {
+	items := make([]logEventJSON, 0, len(events))
+	for _, event := range events {
+		items = append(items, logEventJSON{
+			Time:       event.Time.UTC().Format(time.RFC3339),
+			Action:     event.Action,
+			NoteID:     event.NoteID,
+			Title:      event.Title,
+			Domain:     event.Domain,
+			FromDomain: event.FromDomain,
+			RelPath:    event.RelPath,
+			Source:     event.Source,
+		})
+	}
+
+	if outputFormat == formatNDJSON {
+		for _, item := range items {
+			if err := writeJSONLine(os.Stdout, struct {
+				SchemaVersion int    `json:"schema_version"`
+				Type          string `json:"type"`
+				logEventJSON
+			}{jsonSchemaVersion, "log_event", item}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return writeJSONDocument(map[string]any{
+		"schema_version": jsonSchemaVersion,
+		"command":        "log",
+		"events":         items,
+	})
+}
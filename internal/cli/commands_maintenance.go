@@ -7,6 +7,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"nitid/internal/core"
 )
 
 func runClean(args []string) error {
@@ -65,6 +68,22 @@ func runValidate(args []string) error {
 	if err != nil {
 		return err
 	}
+
+	if jsonOutput() {
+		if err := emitRecord("validate", "validation_report", "report", validationJSON{
+			Total:    report.Total,
+			Warnings: report.Warnings,
+			Errors:   report.Errors,
+			Passed:   len(report.Errors) == 0,
+		}); err != nil {
+			return err
+		}
+		if len(report.Errors) > 0 {
+			return errors.New("validation failed")
+		}
+		return nil
+	}
+
 	if report.Total == 0 {
 		fmt.Println("no notes found")
 		return nil
@@ -100,65 +119,72 @@ func runDoctor(args []string) error {
 		return err
 	}
 
+	checks := doctorChecks(svc)
 	status := "ok"
+	for _, check := range checks {
+		if check.Status == "fail" {
+			status = "fail"
+		}
+		if check.Status == "warn" && status == "ok" {
+			status = "warn"
+		}
+	}
+
+	if jsonOutput() {
+		if err := emitDoctor(checks, status); err != nil {
+			return err
+		}
+	} else {
+		for _, check := range checks {
+			fmt.Printf("[%s] %s\n", check.Status, check.Message)
+		}
+		fmt.Printf("doctor status: %s\n", status)
+	}
+
+	if status == "fail" {
+		return errors.New("doctor found critical issues")
+	}
+	return nil
+}
+
+func doctorChecks(svc *core.Service) []doctorCheck {
+	checks := make([]doctorCheck, 0)
 
 	notesRoot := filepath.Join(svc.Root(), "notes")
 	if info, statErr := os.Stat(notesRoot); statErr != nil || !info.IsDir() {
-		fmt.Printf("[fail] notes directory missing: %s\n", notesRoot)
-		status = "fail"
+		checks = append(checks, doctorCheck{Name: "notes_dir", Status: "fail", Message: fmt.Sprintf("notes directory missing: %s", notesRoot)})
 	} else {
-		fmt.Printf("[ok] notes directory: %s\n", notesRoot)
+		checks = append(checks, doctorCheck{Name: "notes_dir", Status: "ok", Message: fmt.Sprintf("notes directory: %s", notesRoot)})
 	}
 
 	editor := resolveEditor()
 	editorBin := strings.Fields(editor)
 	if len(editorBin) == 0 {
-		fmt.Println("[warn] no editor configured")
-		if status == "ok" {
-			status = "warn"
-		}
+		checks = append(checks, doctorCheck{Name: "editor", Status: "warn", Message: "no editor configured"})
+	} else if _, lookErr := exec.LookPath(editorBin[0]); lookErr != nil {
+		checks = append(checks, doctorCheck{Name: "editor", Status: "warn", Message: fmt.Sprintf("editor not found in PATH: %s", editorBin[0])})
 	} else {
-		if _, lookErr := exec.LookPath(editorBin[0]); lookErr != nil {
-			fmt.Printf("[warn] editor not found in PATH: %s\n", editorBin[0])
-			if status == "ok" {
-				status = "warn"
-			}
-		} else {
-			fmt.Printf("[ok] editor: %s\n", editor)
-		}
+		checks = append(checks, doctorCheck{Name: "editor", Status: "ok", Message: fmt.Sprintf("editor: %s", editor)})
 	}
 
-	fmt.Println("[ok] completion command available: ntd completion bash")
+	checks = append(checks, doctorCheck{Name: "completion", Status: "ok", Message: "completion command available: ntd completion bash"})
+	checks = append(checks, backupDoctorCheck(svc, time.Now().UTC()))
 
 	report, valErr := svc.Validate()
 	if valErr != nil {
-		fmt.Printf("[fail] validate failed: %v\n", valErr)
-		status = "fail"
-	} else {
-		fmt.Printf("[ok] parsed notes: %d\n", report.Total)
-		if len(report.Errors) > 0 {
-			fmt.Printf("[fail] validation errors: %d\n", len(report.Errors))
-			status = "fail"
-		}
-		if len(report.Warnings) > 0 {
-			fmt.Printf("[warn] validation warnings: %d\n", len(report.Warnings))
-			if status == "ok" {
-				status = "warn"
-			}
-		}
+		checks = append(checks, doctorCheck{Name: "validate", Status: "fail", Message: fmt.Sprintf("validate failed: %v", valErr)})
+		return checks
 	}
 
-	switch status {
-	case "ok":
-		fmt.Println("doctor status: ok")
-		return nil
-	case "warn":
-		fmt.Println("doctor status: warn")
-		return nil
-	default:
-		fmt.Println("doctor status: fail")
-		return errors.New("doctor found critical issues")
+	checks = append(checks, doctorCheck{Name: "notes", Status: "ok", Message: fmt.Sprintf("parsed notes: %d", report.Total)})
+	if len(report.Errors) > 0 {
+		checks = append(checks, doctorCheck{Name: "validation_errors", Status: "fail", Message: fmt.Sprintf("validation errors: %d", len(report.Errors))})
 	}
+	if len(report.Warnings) > 0 {
+		checks = append(checks, doctorCheck{Name: "validation_warnings", Status: "warn", Message: fmt.Sprintf("validation warnings: %d", len(report.Warnings))})
+	}
+
+	return checks
 }
 
 func runCompletion(args []string) error {
@@ -197,7 +223,7 @@ _ntd_complete() {
   cmd="${COMP_WORDS[1]}"
 
 	if [[ ${COMP_CWORD} -eq 1 ]]; then
-	    COMPREPLY=( $(compgen -W "help version init capture new daily templates ls find move tag archive delete show edit clean validate doctor tui completion" -- "${cur}") )
+	    COMPREPLY=( $(compgen -W "help version init capture new daily weekly monthly quarterly tasks agenda log stats triage templates ls find export import backup move tag archive delete show edit clean validate doctor tui completion" -- "${cur}") )
 	    return 0
 	  fi
 
@@ -214,6 +240,36 @@ _ntd_complete() {
         return 0
       fi
       ;;
+    export)
+      if [[ ${COMP_CWORD} -eq 2 ]]; then
+        COMPREPLY=( $(compgen -W "csv tsv ndjson html" -- "${cur}") )
+        return 0
+      fi
+      ;;
+    import)
+      if [[ ${COMP_CWORD} -eq 2 ]]; then
+        COMPREPLY=( $(compgen -W "markdown obsidian enex ndjson" -- "${cur}") )
+        return 0
+      fi
+      ;;
+    tasks)
+      if [[ ${COMP_CWORD} -eq 2 ]]; then
+        COMPREPLY=( $(compgen -W "done --open --done --owner --priority" -- "${cur}") )
+        return 0
+      fi
+      ;;
+    templates)
+      if [[ ${COMP_CWORD} -eq 2 ]]; then
+        COMPREPLY=( $(compgen -W "show new" -- "${cur}") )
+        return 0
+      fi
+      ;;
+    backup)
+      if [[ ${COMP_CWORD} -eq 2 ]]; then
+        COMPREPLY=( $(compgen -W "verify restore --out" -- "${cur}") )
+        return 0
+      fi
+      ;;
     completion)
       if [[ ${COMP_CWORD} -eq 2 ]]; then
         COMPREPLY=( $(compgen -W "bash" -- "${cur}") )
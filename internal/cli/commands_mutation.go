@@ -3,6 +3,7 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"nitid/internal/core"
@@ -42,6 +43,7 @@ func runMove(args []string) error {
 	}
 
 	fmt.Printf("moved %s -> %s\n", result.NoteID, result.RelPath)
+	printMutationWarnings(result)
 	return nil
 }
 
@@ -91,6 +93,7 @@ func runArchive(args []string) error {
 	}
 
 	fmt.Printf("archived %s -> %s\n", result.NoteID, result.RelPath)
+	printMutationWarnings(result)
 	return nil
 }
 
@@ -128,9 +131,16 @@ func runDelete(args []string) error {
 	}
 
 	fmt.Printf("deleted %s -> %s\n", result.NoteID, result.RelPath)
+	printMutationWarnings(result)
 	return nil
 }
 
+func printMutationWarnings(result core.MutationResult) {
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "ntd: warning: %s\n", warning)
+	}
+}
+
 func runEdit(args []string) error {
 	if len(args) != 1 {
 		return errors.New("edit requires exactly one <id|@ref> argument")
@@ -17,11 +17,13 @@ func runList(args []string) error {
 	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 
-	domain := fs.String("domain", "", "filter by domain")
-	tag := fs.String("tag", "", "filter by tag")
-	status := fs.String("status", "", "filter by status")
-	kind := fs.String("kind", "", "filter by kind")
+	var filters noteFilterFlags
+	filters.register(fs)
 	long := fs.Bool("long", false, "print detailed rows")
+	withBody := fs.Bool("body", false, "include note bodies in JSON output")
+	var tmplFlags outputTemplateFlags
+	fs.StringVar(&tmplFlags.inline, "template", "", "go text/template or configured template name")
+	fs.StringVar(&tmplFlags.file, "template-file", "", "path to a go text/template file")
 	sortBy := fs.String("sort", "updated", "sort by updated|created|title|id")
 	asc := fs.Bool("asc", false, "sort ascending")
 
@@ -32,26 +34,9 @@ func runList(args []string) error {
 		return errors.New("ls does not accept positional arguments")
 	}
 
-	statusFilter := strings.ToLower(strings.TrimSpace(*status))
-	if statusFilter != "" {
-		if _, ok := allowedStatuses[statusFilter]; !ok {
-			return fmt.Errorf("invalid status %q", statusFilter)
-		}
-	}
-
-	domainFilter := strings.ToLower(strings.TrimSpace(*domain))
-	if domainFilter != "" && !domainIDPattern.MatchString(domainFilter) {
-		return fmt.Errorf("invalid domain %q: use lowercase kebab-case", domainFilter)
-	}
-
-	tagFilter := strings.ToLower(strings.TrimSpace(*tag))
-	if tagFilter != "" && !tagPattern.MatchString(tagFilter) {
-		return fmt.Errorf("invalid tag %q: use lowercase kebab-case", tagFilter)
-	}
-
-	kindFilter := strings.ToLower(strings.TrimSpace(*kind))
-	if kindFilter != "" && !isAllowedKind(kindFilter) {
-		return fmt.Errorf("invalid kind %q", kindFilter)
+	filter, err := filters.build(time.Now().UTC())
+	if err != nil {
+		return err
 	}
 
 	sortMode := strings.ToLower(strings.TrimSpace(*sortBy))
@@ -64,16 +49,27 @@ func runList(args []string) error {
 		return err
 	}
 
-	notes, err := svc.List(core.NoteFilter{
-		Domain: domainFilter,
-		Tag:    tagFilter,
-		Status: statusFilter,
-		Kind:   kindFilter,
-	}, sortMode, *asc)
+	notes, err := svc.List(filter, sortMode, *asc)
 	if err != nil {
 		return err
 	}
 
+	if jsonOutput() {
+		return emitNotes("ls", notes, *withBody)
+	}
+
+	if tmplFlags.enabled() {
+		tmpl, err := tmplFlags.load(svc)
+		if err != nil {
+			return err
+		}
+		items := make([]templateNote, 0, len(notes))
+		for idx, item := range notes {
+			items = append(items, templateNote{NoteFile: item, Note: item.Note, Ref: fmt.Sprintf("@%d", idx+1), Index: idx + 1})
+		}
+		return renderOutputTemplate(tmpl, items)
+	}
+
 	if len(notes) == 0 {
 		fmt.Println("no notes found")
 		return nil
@@ -111,83 +107,116 @@ func runList(args []string) error {
 }
 
 func runFind(args []string) error {
-	domainFilter := ""
-	tagFilter := ""
-	statusFilter := ""
-	kindFilter := ""
+	fs := flag.NewFlagSet("find", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var filters noteFilterFlags
+	filters.register(fs)
+
 	limit := 20
+	fuzzy := false
+	pattern := ""
+	searchIn := ""
+	caseSensitive := false
+	contextLines := 0
+	withBody := false
+	var tmplFlags outputTemplateFlags
 	queryParts := make([]string, 0)
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		switch arg {
-		case "--domain":
+		case "--limit":
 			if i+1 >= len(args) {
-				return errors.New("--domain requires a value")
+				return errors.New("--limit requires a value")
+			}
+			parsed, err := strconv.Atoi(strings.TrimSpace(args[i+1]))
+			if err != nil {
+				return errors.New("--limit must be a number")
 			}
-			domainFilter = strings.ToLower(strings.TrimSpace(args[i+1]))
+			limit = parsed
 			i++
-		case "--tag":
+		case "--fuzzy":
+			fuzzy = true
+		case "--regex":
 			if i+1 >= len(args) {
-				return errors.New("--tag requires a value")
+				return errors.New("--regex requires a pattern")
 			}
-			tagFilter = strings.ToLower(strings.TrimSpace(args[i+1]))
+			pattern = args[i+1]
 			i++
-		case "--status":
+		case "--in":
 			if i+1 >= len(args) {
-				return errors.New("--status requires a value")
+				return errors.New("--in requires a value")
 			}
-			statusFilter = strings.ToLower(strings.TrimSpace(args[i+1]))
+			searchIn = args[i+1]
 			i++
-		case "--kind":
+		case "--case-sensitive":
+			caseSensitive = true
+		case "--body":
+			withBody = true
+		case "--template", "--template-file":
 			if i+1 >= len(args) {
-				return errors.New("--kind requires a value")
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			if arg == "--template" {
+				tmplFlags.inline = args[i+1]
+			} else {
+				tmplFlags.file = args[i+1]
 			}
-			kindFilter = strings.ToLower(strings.TrimSpace(args[i+1]))
 			i++
-		case "--limit":
+		case "--context":
 			if i+1 >= len(args) {
-				return errors.New("--limit requires a value")
+				return errors.New("--context requires a value")
 			}
 			parsed, err := strconv.Atoi(strings.TrimSpace(args[i+1]))
-			if err != nil {
-				return errors.New("--limit must be a number")
+			if err != nil || parsed < 0 {
+				return errors.New("--context must be a non-negative number")
 			}
-			limit = parsed
+			contextLines = parsed
 			i++
 		default:
+			if name := strings.TrimPrefix(arg, "--"); name != arg && fs.Lookup(name) != nil {
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a value", arg)
+				}
+				if err := fs.Set(name, args[i+1]); err != nil {
+					return err
+				}
+				i++
+				continue
+			}
 			queryParts = append(queryParts, arg)
 		}
 	}
 
-	if len(queryParts) == 0 {
-		return errors.New("find requires a query string")
-	}
-
-	query := strings.ToLower(strings.TrimSpace(strings.Join(queryParts, " ")))
-	if query == "" {
-		return errors.New("find query cannot be empty")
-	}
 	if limit < 1 {
 		return errors.New("limit must be at least 1")
 	}
 
-	if statusFilter != "" {
-		if _, ok := allowedStatuses[statusFilter]; !ok {
-			return fmt.Errorf("invalid status %q", statusFilter)
-		}
+	filter, err := filters.build(time.Now().UTC())
+	if err != nil {
+		return err
 	}
 
-	if domainFilter != "" && !domainIDPattern.MatchString(domainFilter) {
-		return fmt.Errorf("invalid domain %q: use lowercase kebab-case", domainFilter)
+	if pattern != "" {
+		if len(queryParts) > 0 {
+			return errors.New("find --regex does not accept a separate query")
+		}
+		if fuzzy {
+			return errors.New("--fuzzy and --regex cannot be combined")
+		}
+		return runRegexFind(pattern, core.RegexOptions{CaseSensitive: caseSensitive, Fields: strings.Split(searchIn, ",")}, filter, limit, contextLines, withBody, tmplFlags)
+	}
+	if searchIn != "" || caseSensitive {
+		return errors.New("--in and --case-sensitive require --regex")
 	}
 
-	if tagFilter != "" && !tagPattern.MatchString(tagFilter) {
-		return fmt.Errorf("invalid tag %q: use lowercase kebab-case", tagFilter)
+	if len(queryParts) == 0 {
+		return errors.New("find requires a query string")
 	}
 
-	if kindFilter != "" && !isAllowedKind(kindFilter) {
-		return fmt.Errorf("invalid kind %q", kindFilter)
+	query := strings.ToLower(strings.TrimSpace(strings.Join(queryParts, " ")))
+	if query == "" {
+		return errors.New("find query cannot be empty")
 	}
 
 	svc, err := newCoreService()
@@ -195,19 +224,31 @@ func runFind(args []string) error {
 		return err
 	}
 
-	matches, err := svc.Find(query, core.NoteFilter{Domain: domainFilter, Tag: tagFilter, Status: statusFilter, Kind: kindFilter}, limit)
+	var results []core.SearchResult
+	if fuzzy {
+		results, err = svc.FuzzyFind(query, filter, limit)
+	} else {
+		results, err = svc.Find(query, filter, limit)
+	}
 	if err != nil {
 		return err
 	}
-	if len(matches) == 0 {
+	if jsonOutput() {
+		return emitSearchResults("find", results, withBody)
+	}
+	if tmplFlags.enabled() {
+		return renderSearchTemplate(svc, tmplFlags, results)
+	}
+	if len(results) == 0 {
 		fmt.Println("no matching notes found")
 		return nil
 	}
 
-	prefixes := uniqueIDPrefixes(matches, 8)
+	prefixes := uniqueIDPrefixes(searchResultFiles(results), 8)
 	fmt.Printf("%-5s  %-12s  %-8s  %-16s  %s\n", "REF", "ID", "STATUS", "DOMAIN", "TITLE")
 	fmt.Printf("%-5s  %-12s  %-8s  %-16s  %s\n", strings.Repeat("-", 5), strings.Repeat("-", 12), strings.Repeat("-", 8), strings.Repeat("-", 16), strings.Repeat("-", 30))
-	for idx, item := range matches {
+	for idx, result := range results {
+		item := result.File
 		idPrefix := prefixes[item.Note.ID]
 		if idPrefix == "" {
 			idPrefix = shortID(item.Note.ID)
@@ -217,10 +258,86 @@ func runFind(args []string) error {
 			idPrefix,
 			item.Note.Status,
 			displayDomain(item.Note.Domain),
-			truncate(item.Note.Title, 72),
+			highlightTitle(item.Note.Title, 72, fieldPositions(result.Matches, "title")),
 		)
+		printSnippets(item.Note.Body, result.Lines, result.LineOffset, contextLines, maxSnippetsPerNote, "       ")
+	}
+
+	return nil
+}
+
+func runRegexFind(pattern string, opts core.RegexOptions, filter NoteFilter, limit, contextLines int, withBody bool, tmplFlags outputTemplateFlags) error {
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+
+	results, err := svc.RegexFind(pattern, opts, filter, limit)
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return emitSearchResults("find", results, withBody)
+	}
+	if tmplFlags.enabled() {
+		return renderSearchTemplate(svc, tmplFlags, results)
+	}
+	if len(results) == 0 {
+		fmt.Println("no matching notes found")
+		return nil
+	}
+
+	prefixes := uniqueIDPrefixes(searchResultFiles(results), 8)
+	for idx, result := range results {
+		if idx > 0 {
+			fmt.Println()
+		}
+		idPrefix := prefixes[result.File.Note.ID]
+		if idPrefix == "" {
+			idPrefix = shortID(result.File.Note.ID)
+		}
+		fmt.Printf("@%d  %s  %s  (%s)\n", idx+1, idPrefix, truncate(result.File.Note.Title, 72), result.File.RelPath)
+		for _, match := range result.Matches {
+			fmt.Printf("  %s: %s\n", match.Field, highlightMatch(match.Value, match.Positions))
+		}
+		printSnippets(result.File.Note.Body, result.Lines, result.LineOffset, contextLines, 0, "  ")
+	}
+
+	return nil
+}
+
+func renderSearchTemplate(svc *core.Service, tmplFlags outputTemplateFlags, results []core.SearchResult) error {
+	tmpl, err := tmplFlags.load(svc)
+	if err != nil {
+		return err
+	}
+	items := make([]templateNote, 0, len(results))
+	for idx, result := range results {
+		items = append(items, templateNote{
+			NoteFile: result.File,
+			Note:     result.File.Note,
+			Ref:      fmt.Sprintf("@%d", idx+1),
+			Index:    idx + 1,
+			Score:    result.Score,
+		})
+	}
+	return renderOutputTemplate(tmpl, items)
+}
+
+func searchResultFiles(results []core.SearchResult) []NoteFile {
+	files := make([]NoteFile, 0, len(results))
+	for _, result := range results {
+		files = append(files, result.File)
 	}
+	return files
+}
 
+func fieldPositions(matches []core.FieldMatch, field string) []int {
+	for _, match := range matches {
+		if match.Field == field {
+			return match.Positions
+		}
+	}
 	return nil
 }
 
@@ -255,6 +372,13 @@ func runShow(args []string) error {
 		return err
 	}
 
+	if jsonOutput() {
+		if raw {
+			return errors.New("show --raw cannot be combined with --format json|ndjson")
+		}
+		return emitRecord("show", "note", "note", toNoteJSON(noteFile, "", true))
+	}
+
 	if raw {
 		rawContent, err := os.ReadFile(noteFile.Path)
 		if err != nil {
@@ -272,8 +396,8 @@ func runShow(args []string) error {
 	fmt.Printf("Domain:  %s\n", displayDomain(note.Domain))
 	fmt.Printf("Tags:    %s\n", displayTags(note.Tags))
 	fmt.Printf("Path:    %s\n", noteFile.RelPath)
-	fmt.Printf("Created: %s\n", note.CreatedAt.Format(time.RFC3339))
-	fmt.Printf("Updated: %s\n", note.UpdatedAt.Format(time.RFC3339))
+	fmt.Printf("Created: %s\n", note.CreatedAt.In(displayLocation()).Format(time.RFC3339))
+	fmt.Printf("Updated: %s\n", note.UpdatedAt.In(displayLocation()).Format(time.RFC3339))
 	fmt.Println()
 	if strings.TrimSpace(note.Body) == "" {
 		fmt.Println("(empty body)")
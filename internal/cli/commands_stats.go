@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"nitid/internal/core"
+)
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+type countJSON struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type weekCountJSON struct {
+	Week  string `json:"week"`
+	Start string `json:"start"`
+	Count int    `json:"count"`
+}
+
+type noteSizeJSON struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	RelPath string `json:"rel_path"`
+	Bytes   int64  `json:"bytes"`
+	Words   int    `json:"words"`
+}
+
+type tagPairJSON struct {
+	Tags  []string `json:"tags"`
+	Count int      `json:"count"`
+}
+
+type statsJSON struct {
+	Total    int             `json:"total"`
+	Statuses []countJSON     `json:"statuses"`
+	Kinds    []countJSON     `json:"kinds"`
+	Domains  []countJSON     `json:"domains"`
+	Tags     []countJSON     `json:"tags"`
+	Weekly   []weekCountJSON `json:"weekly"`
+	Inbox    struct {
+		Count         int     `json:"count"`
+		AgeP50Days    float64 `json:"age_p50_days"`
+		AgeP90Days    float64 `json:"age_p90_days"`
+		OldestAgeDays float64 `json:"oldest_age_days"`
+	} `json:"inbox"`
+	Largest      []noteSizeJSON `json:"largest"`
+	NeverUpdated struct {
+		Count  int        `json:"count"`
+		Oldest []noteJSON `json:"oldest"`
+	} `json:"never_updated"`
+	TagPairs []tagPairJSON `json:"tag_pairs"`
+}
+
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var filters noteFilterFlags
+	filters.register(fs)
+	weeks := fs.Int("weeks", 12, "number of weeks in the creation sparkline")
+	top := fs.Int("top", 5, "number of rows in top lists")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("stats usage: ntd stats [--weeks N] [--top N] [filters]")
+	}
+	if *weeks < 1 || *top < 1 {
+		return errors.New("stats --weeks and --top must be at least 1")
+	}
+
+	now := time.Now().UTC()
+	filter, err := filters.build(now)
+	if err != nil {
+		return err
+	}
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	stats, err := svc.Stats(filter, now, *weeks, *top)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return emitRecord("stats", "stats", "stats", toStatsJSON(stats))
+	}
+
+	fmt.Printf("notes: %d\n", stats.Total)
+	if stats.Total == 0 {
+		return nil
+	}
+	fmt.Println()
+	fmt.Printf("by status:  %s\n", formatCounts(stats.Statuses, 0))
+	fmt.Printf("by kind:    %s\n", formatCounts(stats.Kinds, 0))
+	fmt.Printf("by domain:  %s\n", formatCounts(stats.Domains, 0))
+	if len(stats.Tags) > 0 {
+		fmt.Printf("top tags:   %s\n", formatCounts(stats.Tags, *top))
+	}
+
+	total, peak := 0, 0
+	counts := make([]int, 0, len(stats.Weekly))
+	for _, week := range stats.Weekly {
+		counts = append(counts, week.Count)
+		total += week.Count
+		if week.Count > peak {
+			peak = week.Count
+		}
+	}
+	fmt.Println()
+	fmt.Printf("created per week (%s to %s):\n", stats.Weekly[0].Label, stats.Weekly[len(stats.Weekly)-1].Label)
+	fmt.Printf("  %s  total %d, peak %d/week\n", sparkline(counts), total, peak)
+
+	fmt.Println()
+	if stats.Inbox.Count == 0 {
+		fmt.Println("inbox: empty")
+	} else {
+		fmt.Printf("inbox: %d notes, age p50 %s, p90 %s, oldest %s\n", stats.Inbox.Count, formatAge(stats.Inbox.P50), formatAge(stats.Inbox.P90), formatAge(stats.Inbox.Oldest))
+	}
+
+	fmt.Println()
+	fmt.Println("largest notes:")
+	for _, size := range stats.Largest {
+		fmt.Printf("  %-12s  %8s  %5d words  %s\n", shortID(size.Note.Note.ID), formatBytes(size.Bytes), size.Words, truncate(size.Note.Note.Title, 60))
+	}
+
+	fmt.Println()
+	fmt.Printf("never updated since creation: %d\n", stats.NeverCount)
+	for _, item := range stats.NeverUpdated {
+		fmt.Printf("  %-12s  %s  %s\n", shortID(item.Note.ID), item.Note.CreatedAt.In(displayLocation()).Format("2006-01-02"), truncate(item.Note.Title, 60))
+	}
+
+	if len(stats.TagPairs) > 0 {
+		fmt.Println()
+		fmt.Println("tags used together:")
+		for _, pair := range stats.TagPairs {
+			fmt.Printf("  %s + %s  %d\n", pair.Tags[0], pair.Tags[1], pair.Count)
+		}
+	}
+	return nil
+}
+
+func toStatsJSON(stats core.Stats) statsJSON {
+	var out statsJSON
+	out.Total = stats.Total
+	out.Statuses = toCountsJSON(stats.Statuses)
+	out.Kinds = toCountsJSON(stats.Kinds)
+	out.Domains = toCountsJSON(stats.Domains)
+	out.Tags = toCountsJSON(stats.Tags)
+	out.Weekly = make([]weekCountJSON, 0, len(stats.Weekly))
+	for _, week := range stats.Weekly {
+		out.Weekly = append(out.Weekly, weekCountJSON{Week: week.Label, Start: week.Start.Format("2006-01-02"), Count: week.Count})
+	}
+	out.Inbox.Count = stats.Inbox.Count
+	out.Inbox.AgeP50Days = ageDays(stats.Inbox.P50)
+	out.Inbox.AgeP90Days = ageDays(stats.Inbox.P90)
+	out.Inbox.OldestAgeDays = ageDays(stats.Inbox.Oldest)
+	out.Largest = make([]noteSizeJSON, 0, len(stats.Largest))
+	for _, size := range stats.Largest {
+		out.Largest = append(out.Largest, noteSizeJSON{ID: size.Note.Note.ID, Title: size.Note.Note.Title, RelPath: size.Note.RelPath, Bytes: size.Bytes, Words: size.Words})
+	}
+	out.NeverUpdated.Count = stats.NeverCount
+	out.NeverUpdated.Oldest = make([]noteJSON, 0, len(stats.NeverUpdated))
+	for _, item := range stats.NeverUpdated {
+		out.NeverUpdated.Oldest = append(out.NeverUpdated.Oldest, toNoteJSON(item, "", false))
+	}
+	out.TagPairs = make([]tagPairJSON, 0, len(stats.TagPairs))
+	for _, pair := range stats.TagPairs {
+		out.TagPairs = append(out.TagPairs, tagPairJSON{Tags: []string{pair.Tags[0], pair.Tags[1]}, Count: pair.Count})
+	}
+	return out
+}
+
+func toCountsJSON(counts []core.Count) []countJSON {
+	out := make([]countJSON, 0, len(counts))
+	for _, count := range counts {
+		out = append(out, countJSON{Name: count.Name, Count: count.Count})
+	}
+	return out
+}
+
+func formatCounts(counts []core.Count, limit int) string {
+	parts := make([]string, 0, len(counts))
+	for idx, count := range counts {
+		if limit > 0 && idx == limit {
+			parts = append(parts, fmt.Sprintf("+%d more", len(counts)-limit))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", displayDomain(count.Name), count.Count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sparkline(counts []int) string {
+	peak := 0
+	for _, count := range counts {
+		if count > peak {
+			peak = count
+		}
+	}
+	var b strings.Builder
+	for _, count := range counts {
+		level := 0
+		if peak > 0 {
+			level = (count*(len(sparkLevels)-1) + peak - 1) / peak
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func ageDays(d time.Duration) float64 {
+	return float64(int64(d.Hours()/24*10)) / 10
+}
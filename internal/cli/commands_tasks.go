@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"nitid/internal/core"
+)
+
+func runTasks(args []string) error {
+	if len(args) > 0 && args[0] == "done" {
+		return runTasksDone(args[1:])
+	}
+
+	fs := flag.NewFlagSet("tasks", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var filters noteFilterFlags
+	filters.register(fs)
+	var taskFilter core.TaskFilter
+	fs.BoolVar(&taskFilter.Open, "open", false, "only unchecked tasks")
+	fs.BoolVar(&taskFilter.Done, "done", false, "only checked tasks")
+	fs.StringVar(&taskFilter.Owner, "owner", "", "only tasks assigned to @owner")
+	fs.StringVar(&taskFilter.Priority, "priority", "", "only tasks with !priority")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("tasks usage: ntd tasks [--open|--done] [--owner <name>] [--priority high|medium|low] [filters]")
+	}
+	taskFilter.Priority = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(taskFilter.Priority), "!"))
+	if taskFilter.Priority != "" && taskFilter.Priority != "high" && taskFilter.Priority != "medium" && taskFilter.Priority != "low" {
+		return fmt.Errorf("invalid priority %q: use high, medium, or low", taskFilter.Priority)
+	}
+
+	filter, err := filters.build(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	tasks, err := svc.Tasks(filter, taskFilter)
+	if err != nil {
+		return err
+	}
+	prefixes, err := taskRefPrefixes(svc)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return emitTasks("tasks", tasks, prefixes)
+	}
+
+	if len(tasks) == 0 {
+		fmt.Println("no tasks found")
+		return nil
+	}
+
+	for _, task := range tasks {
+		box := "[ ]"
+		if task.Done {
+			box = "[x]"
+		}
+		context := task.Note.Note.Title
+		if task.Heading != "" {
+			context += " > " + task.Heading
+		}
+		fmt.Printf("%-16s  %s %s  (%s)\n", task.Ref(prefixes[task.Note.Note.ID]), box, task.Text, truncate(context, 60))
+	}
+	return nil
+}
+
+func runTasksDone(args []string) error {
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return errors.New("tasks done usage: ntd tasks done <id|@ref>:<line>")
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	task, result, err := svc.CompleteTask(strings.TrimSpace(args[0]), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		prefixes, err := taskRefPrefixes(svc)
+		if err != nil {
+			return err
+		}
+		return emitRecord("tasks done", "task", "task", toTaskJSON(task, task.Ref(prefixes[task.Note.Note.ID])))
+	}
+	fmt.Printf("completed %q in %s -> %s\n", task.Text, result.NoteID, result.RelPath)
+	return nil
+}
+
+func taskRefPrefixes(svc *core.Service) (map[string]string, error) {
+	notes, err := listNotes(svc.Root(), NoteFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return uniqueIDPrefixes(notes, 8), nil
+}
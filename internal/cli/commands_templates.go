@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"bufio"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -18,31 +20,50 @@ func runNew(args []string) error {
 		return errors.New("new requires a template name")
 	}
 
-	templateName := strings.ToLower(strings.TrimSpace(args[0]))
-	if _, ok := templateDefinitions()[templateName]; !ok {
-		return fmt.Errorf("unknown template %q", templateName)
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	tmpl, warnings, err := svc.Template(args[0])
+	if err != nil {
+		return err
 	}
+	printTemplateWarnings(warnings)
 
 	fs := flag.NewFlagSet("new", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	title := fs.String("title", "", "note title")
 	domain := fs.String("domain", "", "domain id")
 	tags := fs.String("tags", "", "comma-separated tags")
+	vars := templateVars{}
+	fs.Var(vars, "var", "template variable key=value (repeatable)")
 
 	if err := fs.Parse(args[1:]); err != nil {
 		return err
 	}
 
-	extraText := strings.TrimSpace(strings.Join(fs.Args(), " "))
-	def := templateDefinitions()[templateName]
-	body := templateBody(def, extraText)
+	if strings.TrimSpace(*domain) == "" {
+		*domain = tmpl.Domain
+	}
+	expand := newTemplateExpander(svc.Root(), time.Now().In(displayLocation()), vars, os.Stdin)
+	expand.domain = strings.TrimSpace(*domain)
 
 	if strings.TrimSpace(*title) == "" {
-		*title = def.DefaultTitle
-		if templateName == "note" {
-			*title = inferTitle(body)
+		if *title, err = expand.run(tmpl.Title); err != nil {
+			return err
 		}
 	}
+	expand.title = strings.TrimSpace(*title)
+
+	if tmpl.Body, err = expand.run(tmpl.Body); err != nil {
+		return err
+	}
+	extraText := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	body := templateBody(tmpl, extraText)
+
+	if strings.TrimSpace(*title) == "" {
+		*title = inferTitle(body)
+	}
 
 	now := time.Now().UTC()
 	note := Note{
@@ -51,8 +72,8 @@ func runNew(args []string) error {
 		CreatedAt: now,
 		UpdatedAt: now,
 		Domain:    strings.TrimSpace(*domain),
-		Tags:      parseCSV(*tags),
-		Kind:      def.Kind,
+		Tags:      sanitizeTags(append(append([]string{}, tmpl.Tags...), parseCSV(*tags)...)),
+		Kind:      tmpl.Kind,
 		Links:     []string{},
 		Body:      body,
 	}
@@ -61,11 +82,6 @@ func runNew(args []string) error {
 		return err
 	}
 
-	svc, err := newCoreService()
-	if err != nil {
-		return err
-	}
-
 	relPath, err := svc.Create(note)
 	if err != nil {
 		return err
@@ -75,11 +91,113 @@ func runNew(args []string) error {
 	return nil
 }
 
+type templateVars map[string]string
+
+func (v templateVars) String() string {
+	pairs := make([]string, 0, len(v))
+	for key, value := range v {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v templateVars) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !ok || key == "" {
+		return fmt.Errorf("--var expects key=value, got %q", value)
+	}
+	v[key] = val
+	return nil
+}
+
+type templateExpander struct {
+	root    string
+	now     time.Time
+	vars    templateVars
+	in      *bufio.Reader
+	title   string
+	domain  string
+	values  map[string]string
+	answers map[string]string
+}
+
+func newTemplateExpander(root string, now time.Time, vars templateVars, in io.Reader) *templateExpander {
+	return &templateExpander{
+		root:    root,
+		now:     now,
+		vars:    vars,
+		in:      bufio.NewReader(in),
+		values:  map[string]string{},
+		answers: map[string]string{},
+	}
+}
+
+func (e *templateExpander) run(text string) (string, error) {
+	return core.ExpandTemplate(text, e.lookup)
+}
+
+func (e *templateExpander) lookup(name string, prompt bool) (string, bool, error) {
+	if prompt {
+		value, err := e.ask(name)
+		return value, true, err
+	}
+	if value, ok := e.vars[name]; ok {
+		return value, true, nil
+	}
+	if value, ok := e.values[name]; ok {
+		return value, true, nil
+	}
+
+	switch name {
+	case "date":
+		return e.now.Format("2006-01-02"), true, nil
+	case "time":
+		return e.now.Format("15:04"), true, nil
+	case "title":
+		return e.title, true, nil
+	case "domain":
+		return e.domain, true, nil
+	case "user":
+		return core.CurrentUser(), true, nil
+	case "git.branch":
+		return core.GitBranch(e.root), true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+func (e *templateExpander) ask(label string) (string, error) {
+	key := strings.ToLower(label)
+	for _, candidate := range []string{key, slugify(label), strings.ReplaceAll(slugify(label), "-", "_")} {
+		if value, ok := e.vars[candidate]; ok {
+			return value, nil
+		}
+	}
+	if value, ok := e.answers[key]; ok {
+		return value, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	line, err := e.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if errors.Is(err, io.EOF) {
+		fmt.Fprintln(os.Stderr)
+	}
+	value := strings.TrimSpace(line)
+	e.answers[key] = value
+	return value, nil
+}
+
 func runDaily(args []string) error {
 	fs := flag.NewFlagSet("daily", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
-	dateArg := fs.String("date", "", "date in YYYY-MM-DD")
+	dateArg := fs.String("date", "", "date such as 2026-03-01, yesterday, or last friday")
 	openEditor := fs.Bool("edit", false, "open daily note after create/find")
+	carryOver := fs.String("carry-over", "", "copy|move|off unchecked tasks from the previous daily")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -114,6 +232,21 @@ func runDaily(args []string) error {
 		return nil
 	}
 
+	policy, err := resolveCarryOverPolicy(svc, *carryOver)
+	if err != nil {
+		return err
+	}
+	var carry *core.DailyCarryOver
+	if policy != core.CarryOverOff {
+		found, ok, err := svc.DailyCarryOver(targetDate)
+		if err != nil {
+			return err
+		}
+		if ok {
+			carry = &found
+		}
+	}
+
 	note := Note{
 		ID:        newULID(targetDate),
 		Title:     fmt.Sprintf("Daily %s", targetDate.Format("2006-01-02")),
@@ -123,7 +256,10 @@ func runDaily(args []string) error {
 		Tags:      []string{"daily"},
 		Kind:      "daily",
 		Links:     []string{},
-		Body:      defaultDailyBody(targetDate),
+		Body:      defaultDailyBody(targetDate, carry),
+	}
+	if carry != nil {
+		note.Links = append(note.Links, carry.Source.Note.ID)
 	}
 
 	rel, err := svc.Create(note)
@@ -132,6 +268,17 @@ func runDaily(args []string) error {
 	}
 	fmt.Printf("saved %s\n", rel)
 
+	if carry != nil {
+		verb := "copied"
+		if policy == core.CarryOverMove {
+			if _, err := svc.RemoveCarriedTasks(*carry, time.Now()); err != nil {
+				return err
+			}
+			verb = "moved"
+		}
+		fmt.Printf("%s %d open tasks from %s\n", verb, len(carry.Tasks), carry.Source.RelPath)
+	}
+
 	if *openEditor {
 		return core.OpenInEditor(filepath.Join(svc.Root(), filepath.FromSlash(rel)))
 	}
@@ -139,32 +286,251 @@ func runDaily(args []string) error {
 	return nil
 }
 
+func runPeriodic(kind string, args []string) error {
+	fs := flag.NewFlagSet(kind, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dateArg := fs.String("date", "", "any date in the period, such as 2026-W42 or last friday")
+	openEditor := fs.Bool("edit", false, "open the note after create/find")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return fmt.Errorf("%s does not accept positional arguments", kind)
+	}
+
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	if err := svc.Init(); err != nil {
+		return err
+	}
+
+	date, err := resolveDayFlag(kind, *dateArg)
+	if err != nil {
+		return err
+	}
+	period, err := core.PeriodFor(kind, date)
+	if err != nil {
+		return err
+	}
+
+	existing, found, err := svc.FindPeriodic(period)
+	if err != nil {
+		return err
+	}
+	if found {
+		fmt.Printf("%s note already exists: %s\n", kind, existing.RelPath)
+		if *openEditor {
+			return core.OpenInEditor(existing.Path)
+		}
+		return nil
+	}
+
+	rollup, err := svc.Rollup(period)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := svc.Config()
+	if err != nil {
+		return err
+	}
+	body := defaultPeriodicBody()
+	tags := []string{kind}
+	templateName := cfg.String("periodic."+kind+"_template", kind)
+	tmpl, warnings, err := svc.Template(templateName)
+	printTemplateWarnings(warnings)
+	switch {
+	case err == nil:
+		body = tmpl.Body
+		tags = append(tags, tmpl.Tags...)
+	case templateName != kind:
+		return err
+	}
+	if !strings.Contains(body, "{{rollup}}") {
+		body = strings.TrimSpace(body) + "\n\n## Rollup\n\n{{rollup}}"
+	}
+
+	title := period.Title()
+	expand := newTemplateExpander(svc.Root(), time.Now().In(displayLocation()), templateVars{}, os.Stdin)
+	expand.title = title
+	expand.values["period"] = period.Label
+	expand.values["start"] = period.Start.Format("2006-01-02")
+	expand.values["end"] = period.LastDay().Format("2006-01-02")
+	expand.values["rollup"] = rollup.Markdown()
+	if body, err = expand.run(body); err != nil {
+		return err
+	}
+
+	links := make([]string, 0, len(rollup.Dailies))
+	for _, item := range rollup.Dailies {
+		links = append(links, item.Note.ID)
+	}
+	note := Note{
+		ID:        newULID(period.Start),
+		Title:     title,
+		CreatedAt: period.Start,
+		UpdatedAt: period.Start,
+		Tags:      sanitizeTags(tags),
+		Kind:      kind,
+		Links:     links,
+		Body:      strings.TrimSpace(body),
+	}
+
+	rel, err := svc.Create(note)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("saved %s\n", rel)
+	fmt.Printf("rollup %s to %s: %d daily notes, %d created, %d archived, %d completed tasks, %d wins\n",
+		period.Start.Format("2006-01-02"), period.LastDay().Format("2006-01-02"),
+		len(rollup.Dailies), len(rollup.Created), len(rollup.Archived), len(rollup.Completed), len(rollup.Wins))
+
+	if *openEditor {
+		return core.OpenInEditor(filepath.Join(svc.Root(), filepath.FromSlash(rel)))
+	}
+	return nil
+}
+
+func resolveCarryOverPolicy(svc *core.Service, flagValue string) (string, error) {
+	policy := strings.ToLower(strings.TrimSpace(flagValue))
+	source := "--carry-over"
+	if policy == "" {
+		cfg, err := svc.Config()
+		if err != nil {
+			return "", err
+		}
+		policy = strings.ToLower(strings.TrimSpace(cfg.String("daily.carry_over", core.CarryOverCopy)))
+		source = "daily.carry_over"
+	}
+	if !core.IsCarryOverPolicy(policy) {
+		return "", fmt.Errorf("invalid %s %q: use copy, move, or off", source, policy)
+	}
+	return policy, nil
+}
+
 func runTemplates(args []string) error {
-	defs := templateDefinitions()
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 && args[0] == "new" {
+		return runTemplatesNew(svc, args[1:])
+	}
+
+	templates, warnings, err := svc.Templates()
+	if err != nil {
+		return err
+	}
+	printTemplateWarnings(warnings)
+
 	if len(args) == 0 {
-		fmt.Println("available templates:")
-		names := make([]string, 0, len(defs))
-		for name := range defs {
+		names := make([]string, 0, len(templates))
+		for name := range templates {
 			names = append(names, name)
 		}
 		sort.Strings(names)
+
+		if jsonOutput() {
+			items := make([]templateJSON, 0, len(names))
+			for _, name := range names {
+				items = append(items, toTemplateJSON(templates[name]))
+			}
+			return emitRecord("templates", "templates", "templates", items)
+		}
+
+		fmt.Println("available templates:")
 		for _, name := range names {
-			fmt.Printf("- %s\n", name)
+			tmpl := templates[name]
+			source := tmpl.Source
+			if tmpl.Overrides {
+				source += ", overrides built-in"
+			}
+			fmt.Printf("- %-12s %-8s %s\n", name, tmpl.Kind, source)
 		}
 		return nil
 	}
 
 	if len(args) == 2 && args[0] == "show" {
 		name := strings.ToLower(strings.TrimSpace(args[1]))
-		def, ok := defs[name]
+		tmpl, ok := templates[name]
 		if !ok {
 			return fmt.Errorf("unknown template %q", name)
 		}
+		if jsonOutput() {
+			return emitRecord("templates show", "template", "template", toTemplateJSON(tmpl))
+		}
 		fmt.Printf("template: %s\n", name)
-		fmt.Printf("kind: %s\n\n", def.Kind)
-		fmt.Println(strings.TrimSpace(def.BodyTemplate))
+		fmt.Printf("source: %s\n", tmpl.Source)
+		fmt.Printf("kind: %s\n", tmpl.Kind)
+		if tmpl.Title != "" {
+			fmt.Printf("title: %s\n", tmpl.Title)
+		}
+		if tmpl.Domain != "" {
+			fmt.Printf("domain: %s\n", tmpl.Domain)
+		}
+		if len(tmpl.Tags) > 0 {
+			fmt.Printf("tags: %s\n", strings.Join(tmpl.Tags, ", "))
+		}
+		if prompts := core.TemplatePrompts(tmpl.Title + "\n" + tmpl.Body); len(prompts) > 0 {
+			fmt.Printf("prompts: %s\n", strings.Join(prompts, ", "))
+		}
+		fmt.Println()
+		fmt.Println(strings.TrimSpace(tmpl.Body))
 		return nil
 	}
 
-	return errors.New("templates usage: ntd templates OR ntd templates show <name>")
+	return errors.New("templates usage: ntd templates | templates show <name> | templates new <name> [--from <template>] [--edit]")
+}
+
+func runTemplatesNew(svc *core.Service, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("templates new usage: ntd templates new <name> [--from <template>] [--edit]")
+	}
+	name := args[0]
+
+	fs := flag.NewFlagSet("templates new", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "copy an existing template")
+	openEditor := fs.Bool("edit", false, "open the new template in the editor")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("templates new accepts one template name")
+	}
+
+	base := core.Template{
+		Kind:  "note",
+		Title: "",
+		Body:  "## Summary\n\n## Details\n",
+	}
+	if strings.TrimSpace(*from) != "" {
+		tmpl, warnings, err := svc.Template(*from)
+		if err != nil {
+			return err
+		}
+		printTemplateWarnings(warnings)
+		base = tmpl
+	}
+
+	rel, err := svc.NewTemplate(name, base)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %s\n", rel)
+
+	if *openEditor {
+		return core.OpenInEditor(filepath.Join(svc.Root(), filepath.FromSlash(rel)))
+	}
+	return nil
+}
+
+func printTemplateWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
 }
@@ -0,0 +1,382 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/core"
+
+	"github.com/charmbracelet/x/term"
+)
+
+const triagePreviewLines = 8
+
+type triageInput struct {
+	in  *bufio.Reader
+	fd  uintptr
+	tty bool
+}
+
+func newTriageInput(file *os.File) *triageInput {
+	return &triageInput{
+		in:  bufio.NewReader(file),
+		fd:  file.Fd(),
+		tty: term.IsTerminal(file.Fd()),
+	}
+}
+
+func runTriage(args []string) error {
+	fs := flag.NewFlagSet("triage", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	restart := fs.Bool("restart", false, "discard saved progress and start from the oldest inbox note")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) > 0 {
+		return errors.New("triage usage: ntd triage [--restart]")
+	}
+	svc, err := newCoreService()
+	if err != nil {
+		return err
+	}
+	if *restart {
+		if err := svc.ClearTriage(); err != nil {
+			return err
+		}
+	}
+	state, resumed, err := svc.LoadTriage()
+	if err != nil {
+		return err
+	}
+	queue, err := svc.TriageQueue(state)
+	if err != nil {
+		return err
+	}
+	if len(queue) == 0 {
+		if !resumed {
+			fmt.Println("inbox is empty")
+			return nil
+		}
+		if err := svc.ClearTriage(); err != nil {
+			return err
+		}
+		return printTriageSummary(svc, "triage complete", state)
+	}
+	if resumed {
+		fmt.Printf("resuming triage started %s (%d handled, %d left)\n", state.Started.In(displayLocation()).Format("2006-01-02 15:04"), len(state.Seen), len(queue))
+	} else {
+		state.Started = time.Now().UTC()
+	}
+
+	domains, tags, err := svc.Vocabulary()
+	if err != nil {
+		return err
+	}
+	input := newTriageInput(os.Stdin)
+	now := time.Now().UTC()
+
+	for idx := range queue {
+		item := queue[idx]
+		printTriagePreview(item, idx+1, len(queue), now)
+		tagged := false
+		done := false
+		for !done {
+			key, err := input.key("[m]ove [t]ag [a]rchive [x] delete [s]kip [e]dit [q]uit > ")
+			if err != nil {
+				return err
+			}
+			switch key {
+			case 'm':
+				domainID, err := input.line("domain: ", domains)
+				if err != nil {
+					return err
+				}
+				domainID = strings.ToLower(domainID)
+				if domainID == "" {
+					continue
+				}
+				if !domainIDPattern.MatchString(domainID) {
+					fmt.Printf("  invalid domain %q: use lowercase kebab-case\n", domainID)
+					continue
+				}
+				result, err := svc.Move(item.Note.ID, domainID)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("  moved -> %s\n", result.RelPath)
+				printMutationWarnings(result)
+				domains = addVocabulary(domains, domainID)
+				state.Moved++
+				done = true
+			case 't':
+				value, err := input.line("tags: ", tags)
+				if err != nil {
+					return err
+				}
+				added, err := addTriageTags(svc, item.Note.ID, value)
+				if err != nil {
+					fmt.Printf("  %v\n", err)
+					continue
+				}
+				if len(added) == 0 {
+					continue
+				}
+				for _, tag := range added {
+					tags = addVocabulary(tags, tag)
+				}
+				if item, err = svc.FindBySelector(item.Note.ID); err != nil {
+					return err
+				}
+				fmt.Printf("  tags: %s\n", displayTags(item.Note.Tags))
+				if !tagged {
+					state.Tagged++
+					tagged = true
+				}
+			case 'a':
+				result, err := svc.Archive(item.Note.ID)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("  archived -> %s\n", result.RelPath)
+				printMutationWarnings(result)
+				state.Archived++
+				done = true
+			case 'x':
+				confirm, err := input.key(fmt.Sprintf("delete %q? [y/N] ", item.Note.Title))
+				if err != nil {
+					return err
+				}
+				if confirm != 'y' && confirm != 'Y' {
+					fmt.Println("  kept")
+					continue
+				}
+				result, err := svc.Delete(item.Note.ID)
+				if err != nil {
+					return err
+				}
+				fmt.Println("  deleted")
+				printMutationWarnings(result)
+				state.Deleted++
+				done = true
+			case 's':
+				state.Skipped++
+				done = true
+			case 'e':
+				if err := svc.Edit(item.Note.ID); err != nil {
+					fmt.Printf("  %v\n", err)
+					continue
+				}
+				if item, err = svc.FindBySelector(item.Note.ID); err != nil {
+					return err
+				}
+				printTriagePreview(item, idx+1, len(queue), now)
+			case 'q':
+				if err := svc.SaveTriage(state); err != nil {
+					return err
+				}
+				if err := printTriageSummary(svc, "triage paused", state); err != nil {
+					return err
+				}
+				fmt.Println("run ntd triage to resume")
+				return nil
+			default:
+				fmt.Println("  m: move to a domain (tab completes)  t: add tags  a: archive  x: delete")
+				fmt.Println("  s: skip  e: open in editor  q: quit and save progress")
+			}
+		}
+		state.MarkSeen(item.Note.ID)
+		if err := svc.SaveTriage(state); err != nil {
+			return err
+		}
+	}
+
+	if err := svc.ClearTriage(); err != nil {
+		return err
+	}
+	return printTriageSummary(svc, "triage complete", state)
+}
+
+func printTriagePreview(item core.NoteFile, position, total int, now time.Time) {
+	note := item.Note
+	fmt.Println()
+	fmt.Printf("[%d/%d] %s\n", position, total, note.Title)
+	fmt.Printf("  %s  created %s (%s ago)  tags: %s\n", shortID(note.ID), note.CreatedAt.In(displayLocation()).Format("2006-01-02"), formatAge(now.Sub(note.CreatedAt)), displayTags(note.Tags))
+
+	body := strings.TrimSpace(note.Body)
+	if body == "" {
+		return
+	}
+	lines := strings.Split(body, "\n")
+	fmt.Println()
+	for i, line := range lines {
+		if i == triagePreviewLines {
+			fmt.Printf("  ... (%d more lines)\n", len(lines)-triagePreviewLines)
+			break
+		}
+		fmt.Printf("  %s\n", truncate(line, 100))
+	}
+}
+
+func printTriageSummary(svc *core.Service, label string, state core.TriageState) error {
+	inbox, err := svc.List(NoteFilter{Status: statusInbox}, "created", true)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Printf("%s: %d moved, %d tagged, %d archived, %d deleted, %d skipped; %d left in inbox\n", label, state.Moved, state.Tagged, state.Archived, state.Deleted, state.Skipped, len(inbox))
+	return nil
+}
+
+func addTriageTags(svc *core.Service, id, value string) ([]string, error) {
+	tags := parseCSV(strings.ToLower(value))
+	for _, tag := range tags {
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: use lowercase kebab-case", tag)
+		}
+	}
+	for _, tag := range tags {
+		if _, err := svc.Tag(id, "add", tag); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+func addVocabulary(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	values = append(values, value)
+	sort.Strings(values)
+	return values
+}
+
+func (t *triageInput) key(prompt string) (byte, error) {
+	fmt.Print(prompt)
+	if !t.tty {
+		line, err := t.in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		line = strings.TrimSpace(line)
+		if errors.Is(err, io.EOF) && line == "" {
+			fmt.Println()
+			return 'q', nil
+		}
+		if line == "" {
+			return 0, nil
+		}
+		return line[0], nil
+	}
+
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return 0, err
+	}
+	key, err := t.in.ReadByte()
+	_ = term.Restore(t.fd, state)
+	if err != nil {
+		return 0, err
+	}
+	if key == 3 || key == 4 {
+		key = 'q'
+	}
+	if key >= ' ' && key < 127 {
+		fmt.Printf("%c", key)
+	}
+	fmt.Println()
+	return key, nil
+}
+
+func (t *triageInput) line(prompt string, candidates []string) (string, error) {
+	fmt.Print(prompt)
+	if !t.tty {
+		line, err := t.in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Println()
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = term.Restore(t.fd, state) }()
+
+	buf := []rune{}
+	for {
+		r, _, err := t.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case r == '\r' || r == '\n':
+			fmt.Print("\r\n")
+			return strings.TrimSpace(string(buf)), nil
+		case r == 3:
+			fmt.Print("\r\n")
+			return "", nil
+		case r == 27:
+			if t.in.Buffered() > 0 {
+				_, _ = t.in.Discard(t.in.Buffered())
+				continue
+			}
+			fmt.Print("\r\n")
+			return "", nil
+		case r == 127 || r == 8:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				fmt.Print("\b \b")
+			}
+		case r == '\t':
+			text := string(buf)
+			start := strings.LastIndex(text, ",") + 1
+			word := strings.TrimLeft(text[start:], " ")
+			completed, matches := completeWord(word, candidates)
+			if len(matches) > 1 && completed == word {
+				fmt.Printf("\r\n%s\r\n%s%s", strings.Join(matches, "  "), prompt, text)
+				continue
+			}
+			if suffix := strings.TrimPrefix(completed, word); suffix != "" {
+				buf = append(buf, []rune(suffix)...)
+				fmt.Print(suffix)
+			}
+		case r >= ' ':
+			buf = append(buf, r)
+			fmt.Print(string(r))
+		}
+	}
+}
+
+func completeWord(prefix string, candidates []string) (string, []string) {
+	matches := make([]string, 0)
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return prefix, nil
+	}
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return common, matches
+}
@@ -26,11 +26,13 @@ var (
 	tuiAccentColor = lipgloss.Color("81")
 	tuiBorderColor = lipgloss.Color("240")
 	tuiStatusBg    = lipgloss.Color("236")
+	tuiMatchColor  = lipgloss.Color("214")
 )
 
 type notesLoadedMsg struct {
-	notes []core.NoteFile
-	err   error
+	notes   []core.NoteFile
+	matches map[string][]core.FieldMatch
+	err     error
 }
 
 type opDoneMsg struct {
@@ -41,6 +43,7 @@ type opDoneMsg struct {
 type tuiModel struct {
 	svc             *core.Service
 	notes           []core.NoteFile
+	matches         map[string][]core.FieldMatch
 	selected        int
 	pendingSelectID string
 	width           int
@@ -110,6 +113,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.notes = typed.notes
+		m.matches = typed.matches
 		m.reselectPending()
 		m.clampSelection()
 		if len(m.notes) == 0 {
@@ -565,6 +569,9 @@ func (m tuiModel) renderList(maxLines int) string {
 		line := fmt.Sprintf("%s @%d %-8s %s", marker, idx+1, item.Note.Status, truncate(item.Note.Title, 38))
 		if idx == m.selected {
 			line = lipgloss.NewStyle().Bold(true).Foreground(tuiAccentColor).Render(line)
+		} else if positions := m.fieldPositions(item.Note.ID, "title"); len(positions) > 0 {
+			title, positions := truncatePositions(item.Note.Title, positions, 38)
+			line = fmt.Sprintf("%s @%d %-8s %s", marker, idx+1, item.Note.Status, m.highlight(title, positions))
 		}
 		lines = append(lines, line)
 		if len(lines) >= maxLines {
@@ -581,12 +588,15 @@ func (m tuiModel) renderPreview(maxLines int) string {
 		return "Preview\n\nNo note selected"
 	}
 
-	lines := []string{"Preview", "", truncate(noteFile.Note.Title, 100), ""}
-	body := strings.TrimSpace(noteFile.Note.Body)
+	title, positions := truncatePositions(noteFile.Note.Title, m.fieldPositions(noteFile.Note.ID, "title"), 100)
+	title = m.highlight(title, positions)
+	lines := []string{"Preview", "", title, ""}
+	body, positions := trimPositions(noteFile.Note.Body, m.fieldPositions(noteFile.Note.ID, "body"))
 	if body == "" {
 		lines = append(lines, "(empty body)")
 		return strings.Join(lines, "\n")
 	}
+	body = m.highlight(body, positions)
 
 	bodyLines := strings.Split(body, "\n")
 	space := maxLines - len(lines)
@@ -600,6 +610,15 @@ func (m tuiModel) renderPreview(maxLines int) string {
 	return strings.Join(lines, "\n")
 }
 
+func (m tuiModel) fieldPositions(noteID, field string) []int {
+	return fieldPositions(m.matches[noteID], field)
+}
+
+func (m tuiModel) highlight(text string, positions []int) string {
+	style := lipgloss.NewStyle().Bold(true).Foreground(tuiMatchColor)
+	return highlightRunes(text, positions, func(value string) string { return style.Render(value) })
+}
+
 func (m tuiModel) renderMeta(maxLines int) string {
 	noteFile, ok := m.selectedNote()
 	if !ok {
@@ -614,7 +633,7 @@ func (m tuiModel) renderMeta(maxLines int) string {
 		fmt.Sprintf("kind: %s", noteFile.Note.Kind),
 		fmt.Sprintf("domain: %s", displayDomain(noteFile.Note.Domain)),
 		fmt.Sprintf("tags: %s", displayTags(noteFile.Note.Tags)),
-		fmt.Sprintf("updated: %s", noteFile.Note.UpdatedAt.Format("2006-01-02 15:04")),
+		fmt.Sprintf("updated: %s", noteFile.Note.UpdatedAt.In(displayLocation()).Format("2006-01-02 15:04")),
 		"",
 		"Actions",
 		"- e edit in TUI",
@@ -640,8 +659,17 @@ func loadListCmd(svc *core.Service) tea.Cmd {
 
 func findNotesCmd(svc *core.Service, query string) tea.Cmd {
 	return func() tea.Msg {
-		notes, err := svc.Find(query, core.NoteFilter{}, 200)
-		return notesLoadedMsg{notes: notes, err: err}
+		results, err := svc.FuzzyFind(query, core.NoteFilter{}, 200)
+		if err != nil {
+			return notesLoadedMsg{err: err}
+		}
+		notes := make([]core.NoteFile, 0, len(results))
+		matches := make(map[string][]core.FieldMatch, len(results))
+		for _, result := range results {
+			notes = append(notes, result.File)
+			matches[result.File.Note.ID] = result.Matches
+		}
+		return notesLoadedMsg{notes: notes, matches: matches}
 	}
 }
 
@@ -651,7 +679,7 @@ func moveNoteCmd(svc *core.Service, selector, domain string) tea.Cmd {
 		if err != nil {
 			return opDoneMsg{err: err}
 		}
-		return opDoneMsg{status: fmt.Sprintf("moved %s -> %s", result.NoteID, result.RelPath)}
+		return opDoneMsg{status: mutationStatus(fmt.Sprintf("moved %s -> %s", result.NoteID, result.RelPath), result)}
 	}
 }
 
@@ -671,8 +699,15 @@ func archiveNoteCmd(svc *core.Service, selector string) tea.Cmd {
 		if err != nil {
 			return opDoneMsg{err: err}
 		}
-		return opDoneMsg{status: fmt.Sprintf("archived %s -> %s", result.NoteID, result.RelPath)}
+		return opDoneMsg{status: mutationStatus(fmt.Sprintf("archived %s -> %s", result.NoteID, result.RelPath), result)}
+	}
+}
+
+func mutationStatus(status string, result core.MutationResult) string {
+	for _, warning := range result.Warnings {
+		status += "; warning: " + warning
 	}
+	return status
 }
 
 func updateBodyCmd(svc *core.Service, selector, body string) tea.Cmd {
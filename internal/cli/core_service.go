@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"nitid/internal/core"
 )
@@ -13,3 +16,25 @@ func newCoreService() (*core.Service, error) {
 	}
 	return core.New(root), nil
 }
+
+func displayLocation() *time.Location {
+	if cachedLocation != nil {
+		return cachedLocation
+	}
+	cachedLocation = time.UTC
+	svc, err := newCoreService()
+	if err == nil {
+		var loc *time.Location
+		if loc, err = svc.Location(); err == nil {
+			cachedLocation = loc
+			return cachedLocation
+		}
+	}
+	err = fmt.Errorf("%v; using UTC", err)
+	if jsonOutput() {
+		reportJSONError(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "ntd: warning: %v\n", err)
+	}
+	return cachedLocation
+}
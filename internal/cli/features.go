@@ -1,45 +1,53 @@
 package cli
 
 import (
-	"errors"
+	"flag"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"nitid/internal/core"
 )
 
-type templateDef struct {
-	Kind         string
-	DefaultTitle string
-	BodyTemplate string
+var relativeDurationPattern = regexp.MustCompile(`^(\d+)([dw])$`)
+
+var cachedLocation *time.Location
+
+type dateRangeFlags struct {
+	createdAfter  string
+	createdBefore string
+	updatedAfter  string
+	updatedBefore string
 }
 
-func templateDefinitions() map[string]templateDef {
-	return map[string]templateDef{
-		"note": {
-			Kind:         "note",
-			DefaultTitle: "Untitled",
-			BodyTemplate: "",
-		},
-		"adr": {
-			Kind:         "adr",
-			DefaultTitle: "Architecture decision",
-			BodyTemplate: "## Context\n\n## Decision\n\n## Consequences\n",
-		},
-		"meeting": {
-			Kind:         "note",
-			DefaultTitle: "Meeting notes",
-			BodyTemplate: "## Date\n\n## Attendees\n\n## Notes\n\n## Action items\n- [ ] ",
-		},
-		"bug": {
-			Kind:         "note",
-			DefaultTitle: "Bug report",
-			BodyTemplate: "## Symptoms\n\n## Steps to reproduce\n\n## Root cause\n\n## Fix\n\n## Validation\n",
-		},
-	}
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
 }
 
-func templateBody(def templateDef, extraText string) string {
-	base := strings.TrimSpace(def.BodyTemplate)
+func (s *stringList) Set(value string) error {
+	*s = append(*s, parseCSV(value)...)
+	return nil
+}
+
+type noteFilterFlags struct {
+	domain    string
+	status    string
+	kind      string
+	tags      stringList
+	anyTags   stringList
+	notTags   stringList
+	notDomain string
+	notStatus string
+	notKind   string
+	dates     dateRangeFlags
+}
+
+func templateBody(tmpl core.Template, extraText string) string {
+	base := strings.TrimSpace(tmpl.Body)
 	extra := strings.TrimSpace(extraText)
 
 	if base == "" {
@@ -52,29 +60,152 @@ func templateBody(def templateDef, extraText string) string {
 }
 
 func resolveDailyDate(value string) (time.Time, error) {
+	return resolveDayFlag("daily", value)
+}
+
+func resolveDayFlag(command, value string) (time.Time, error) {
 	if strings.TrimSpace(value) == "" {
-		now := time.Now().UTC()
-		return time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.UTC), nil
+		value = "today"
 	}
 
-	t, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	t, err := core.ParseDate(value, time.Now().In(displayLocation()))
 	if err != nil {
-		return time.Time{}, errors.New("daily --date must use YYYY-MM-DD")
+		return time.Time{}, fmt.Errorf("%s --date: %w", command, err)
 	}
 	return time.Date(t.Year(), t.Month(), t.Day(), 9, 0, 0, 0, time.UTC), nil
 }
 
-func defaultDailyBody(date time.Time) string {
+func defaultDailyBody(date time.Time, carry *core.DailyCarryOver) string {
+	plan := "- [ ] Top priority 1\n- [ ] Top priority 2"
+	if carry != nil && len(carry.Tasks) > 0 {
+		source := carry.Source.Note
+		lines := make([]string, 0, len(carry.Tasks)+2)
+		lines = append(lines, fmt.Sprintf("Carried over from [[%s|%s]]:", source.ID, source.Title), "")
+		for _, task := range carry.Tasks {
+			lines = append(lines, "- [ ] "+task)
+		}
+		plan = strings.Join(lines, "\n")
+	}
+
 	return strings.TrimSpace(fmt.Sprintf(`
 ## Plan (%s)
 
-- [ ] Top priority 1
-- [ ] Top priority 2
+%s
 
 ## Notes
 
 ## Wins
 
 ## Follow-ups
-`, date.Format("2006-01-02")))
+`, date.Format("2006-01-02"), plan))
+}
+
+func defaultPeriodicBody() string {
+	return "## Summary\n\n## Rollup ({{start}} to {{end}})\n\n{{rollup}}\n\n## Next steps\n"
+}
+
+func (d dateRangeFlags) apply(filter *NoteFilter, now time.Time) error {
+	var err error
+	if filter.CreatedAfter, err = resolveFilterTime("--created-after", d.createdAfter, now); err != nil {
+		return err
+	}
+	if filter.CreatedBefore, err = resolveFilterTime("--created-before", d.createdBefore, now); err != nil {
+		return err
+	}
+	if filter.UpdatedAfter, err = resolveFilterTime("--updated-after", d.updatedAfter, now); err != nil {
+		return err
+	}
+	if filter.UpdatedBefore, err = resolveFilterTime("--updated-before", d.updatedBefore, now); err != nil {
+		return err
+	}
+	return nil
+}
+
+func resolveFilterTime(flagName, value string, now time.Time) (time.Time, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if m := relativeDurationPattern.FindStringSubmatch(value); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: invalid duration %q", flagName, value)
+		}
+		days := n
+		if m[2] == "w" {
+			days = n * 7
+		}
+		return now.AddDate(0, 0, -days), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(value)); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := core.ParseDate(value, now.In(displayLocation())); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, displayLocation()).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%s must be YYYY-MM-DD, RFC3339, a duration like 7d or 2w, or a date like yesterday, last friday, or 2026-W42", flagName)
+}
+
+func (f *noteFilterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.domain, "domain", "", "filter by domain")
+	fs.StringVar(&f.status, "status", "", "filter by status")
+	fs.StringVar(&f.kind, "kind", "", "filter by kind")
+	fs.Var(&f.tags, "tag", "require tag (repeatable)")
+	fs.Var(&f.anyTags, "any-tag", "require at least one of these tags (repeatable)")
+	fs.Var(&f.notTags, "not-tag", "exclude notes with tag (repeatable)")
+	fs.StringVar(&f.notDomain, "not-domain", "", "exclude domain")
+	fs.StringVar(&f.notStatus, "not-status", "", "exclude status")
+	fs.StringVar(&f.notKind, "not-kind", "", "exclude kind")
+	fs.StringVar(&f.dates.createdAfter, "created-after", "", "created on or after date or duration")
+	fs.StringVar(&f.dates.createdBefore, "created-before", "", "created before date or duration")
+	fs.StringVar(&f.dates.updatedAfter, "updated-after", "", "updated on or after date or duration")
+	fs.StringVar(&f.dates.updatedBefore, "updated-before", "", "updated before date or duration")
+}
+
+func (f noteFilterFlags) build(now time.Time) (NoteFilter, error) {
+	filter := NoteFilter{
+		Domain:    strings.ToLower(strings.TrimSpace(f.domain)),
+		Status:    strings.ToLower(strings.TrimSpace(f.status)),
+		Kind:      strings.ToLower(strings.TrimSpace(f.kind)),
+		Tags:      f.tags,
+		AnyTags:   f.anyTags,
+		NotTags:   f.notTags,
+		NotDomain: strings.ToLower(strings.TrimSpace(f.notDomain)),
+		NotStatus: strings.ToLower(strings.TrimSpace(f.notStatus)),
+		NotKind:   strings.ToLower(strings.TrimSpace(f.notKind)),
+	}
+
+	for _, status := range []string{filter.Status, filter.NotStatus} {
+		if status == "" {
+			continue
+		}
+		if _, ok := allowedStatuses[status]; !ok {
+			return NoteFilter{}, fmt.Errorf("invalid status %q", status)
+		}
+	}
+	for _, domain := range []string{filter.Domain, filter.NotDomain} {
+		if domain != "" && !domainIDPattern.MatchString(domain) {
+			return NoteFilter{}, fmt.Errorf("invalid domain %q: use lowercase kebab-case", domain)
+		}
+	}
+	for _, kind := range []string{filter.Kind, filter.NotKind} {
+		if kind != "" && !isAllowedKind(kind) {
+			return NoteFilter{}, fmt.Errorf("invalid kind %q", kind)
+		}
+	}
+	for _, group := range [][]string{filter.Tags, filter.AnyTags, filter.NotTags} {
+		for _, tag := range group {
+			if !tagPattern.MatchString(tag) {
+				return NoteFilter{}, fmt.Errorf("invalid tag %q: use lowercase kebab-case", tag)
+			}
+		}
+	}
+
+	if err := f.dates.apply(&filter, now); err != nil {
+		return NoteFilter{}, err
+	}
+	return filter, nil
 }
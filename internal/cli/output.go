@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"nitid/internal/core"
+)
+
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+
+	jsonSchemaVersion = 1
+)
+
+var outputFormat = formatText
+
+type noteJSON struct {
+	Ref       string   `json:"ref,omitempty"`
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+	Domain    string   `json:"domain"`
+	Tags      []string `json:"tags"`
+	Status    string   `json:"status"`
+	Kind      string   `json:"kind"`
+	Links     []string `json:"links"`
+	RelPath   string   `json:"rel_path"`
+	Body      *string  `json:"body,omitempty"`
+}
+
+type templateJSON struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	Title     string   `json:"title"`
+	Domain    string   `json:"domain"`
+	Tags      []string `json:"tags"`
+	Source    string   `json:"source"`
+	Overrides bool     `json:"overrides"`
+	Body      string   `json:"body"`
+}
+
+type fieldMatchJSON struct {
+	Field     string `json:"field"`
+	Value     string `json:"value"`
+	Positions []int  `json:"positions"`
+}
+
+type lineMatchJSON struct {
+	Line      int    `json:"line"`
+	Text      string `json:"text"`
+	Positions []int  `json:"positions"`
+}
+
+type searchResultJSON struct {
+	Note    noteJSON         `json:"note"`
+	Score   int              `json:"score"`
+	Matches []fieldMatchJSON `json:"matches"`
+	Lines   []lineMatchJSON  `json:"lines"`
+}
+
+type taskJSON struct {
+	Ref       string   `json:"ref"`
+	NoteID    string   `json:"note_id"`
+	NoteTitle string   `json:"note_title"`
+	RelPath   string   `json:"rel_path"`
+	Line      int      `json:"line"`
+	Heading   string   `json:"heading"`
+	Text      string   `json:"text"`
+	Done      bool     `json:"done"`
+	Due       string   `json:"due,omitempty"`
+	Owners    []string `json:"owners"`
+	Priority  string   `json:"priority,omitempty"`
+}
+
+type validationJSON struct {
+	Total    int      `json:"total"`
+	Warnings []string `json:"warnings"`
+	Errors   []string `json:"errors"`
+	Passed   bool     `json:"passed"`
+}
+
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+var commandFormatFlags = map[string]bool{"log": true}
+
+var jsonFormatCommands = map[string]bool{
+	"ls": true, "find": true, "show": true, "validate": true, "doctor": true,
+	"templates": true, "import": true, "backup": true, "tasks": true,
+	"agenda": true, "stats": true, "log": true,
+}
+
+func extractFormatFlag(args []string) ([]string, string, error) {
+	format := formatText
+	command := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case commandFormatFlags[command]:
+			rest = append(rest, arg)
+		case arg == "--format":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("--format requires a value")
+			}
+			format = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		default:
+			if command == "" && !strings.HasPrefix(arg, "-") {
+				command = arg
+			}
+			rest = append(rest, arg)
+		}
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case formatText, formatJSON, formatNDJSON:
+		return rest, format, nil
+	default:
+		return nil, "", fmt.Errorf("invalid format %q: use text, json, or ndjson", format)
+	}
+}
+
+func jsonOutput() bool {
+	return outputFormat == formatJSON || outputFormat == formatNDJSON
+}
+
+func writeJSONLine(w io.Writer, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func writeJSONDocument(value any) error {
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(b))
+	return err
+}
+
+func reportJSONError(err error) {
+	_ = writeJSONLine(os.Stderr, map[string]any{
+		"schema_version": jsonSchemaVersion,
+		"type":           "error",
+		"error":          err.Error(),
+	})
+}
+
+func toNoteJSON(item NoteFile, ref string, includeBody bool) noteJSON {
+	out := noteJSON{
+		Ref:       ref,
+		ID:        item.Note.ID,
+		Title:     item.Note.Title,
+		CreatedAt: item.Note.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: item.Note.UpdatedAt.Format(time.RFC3339),
+		Domain:    item.Note.Domain,
+		Tags:      nonNilStrings(item.Note.Tags),
+		Status:    item.Note.Status,
+		Kind:      item.Note.Kind,
+		Links:     nonNilStrings(item.Note.Links),
+		RelPath:   item.RelPath,
+	}
+	if includeBody {
+		body := item.Note.Body
+		out.Body = &body
+	}
+	return out
+}
+
+func toSearchResultJSON(result core.SearchResult, ref string, includeBody bool) searchResultJSON {
+	out := searchResultJSON{
+		Note:    toNoteJSON(result.File, ref, includeBody),
+		Score:   result.Score,
+		Matches: []fieldMatchJSON{},
+		Lines:   []lineMatchJSON{},
+	}
+	for _, match := range result.Matches {
+		out.Matches = append(out.Matches, fieldMatchJSON{Field: match.Field, Value: match.Value, Positions: nonNilInts(match.Positions)})
+	}
+	for _, line := range result.Lines {
+		out.Lines = append(out.Lines, lineMatchJSON{Line: line.Line + result.LineOffset, Text: line.Text, Positions: nonNilInts(line.Positions)})
+	}
+	return out
+}
+
+func emitNotes(command string, notes []NoteFile, includeBody bool) error {
+	items := make([]noteJSON, 0, len(notes))
+	for idx, item := range notes {
+		items = append(items, toNoteJSON(item, fmt.Sprintf("@%d", idx+1), includeBody))
+	}
+
+	if outputFormat == formatNDJSON {
+		for _, item := range items {
+			if err := writeJSONLine(os.Stdout, struct {
+				SchemaVersion int    `json:"schema_version"`
+				Type          string `json:"type"`
+				noteJSON
+			}{jsonSchemaVersion, "note", item}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return writeJSONDocument(map[string]any{
+		"schema_version": jsonSchemaVersion,
+		"command":        command,
+		"notes":          items,
+	})
+}
+
+func emitSearchResults(command string, results []core.SearchResult, includeBody bool) error {
+	items := make([]searchResultJSON, 0, len(results))
+	for idx, result := range results {
+		items = append(items, toSearchResultJSON(result, fmt.Sprintf("@%d", idx+1), includeBody))
+	}
+
+	if outputFormat == formatNDJSON {
+		for _, item := range items {
+			if err := writeJSONLine(os.Stdout, struct {
+				SchemaVersion int    `json:"schema_version"`
+				Type          string `json:"type"`
+				searchResultJSON
+			}{jsonSchemaVersion, "search_result", item}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return writeJSONDocument(map[string]any{
+		"schema_version": jsonSchemaVersion,
+		"command":        command,
+		"results":        items,
+	})
+}
+
+func toTaskJSON(task core.Task, ref string) taskJSON {
+	out := taskJSON{
+		Ref:       ref,
+		NoteID:    task.Note.Note.ID,
+		NoteTitle: task.Note.Note.Title,
+		RelPath:   task.Note.RelPath,
+		Line:      task.Line,
+		Heading:   task.Heading,
+		Text:      task.Text,
+		Done:      task.Done,
+		Owners:    nonNilStrings(task.Owners),
+		Priority:  task.Priority,
+	}
+	if !task.Due.IsZero() {
+		out.Due = task.Due.Format("2006-01-02")
+	}
+	return out
+}
+
+func emitTasks(command string, tasks []core.Task, prefixes map[string]string) error {
+	items := make([]taskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		items = append(items, toTaskJSON(task, task.Ref(prefixes[task.Note.Note.ID])))
+	}
+
+	if outputFormat == formatNDJSON {
+		for _, item := range items {
+			if err := writeJSONLine(os.Stdout, struct {
+				SchemaVersion int    `json:"schema_version"`
+				Type          string `json:"type"`
+				taskJSON
+			}{jsonSchemaVersion, "task", item}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return writeJSONDocument(map[string]any{
+		"schema_version": jsonSchemaVersion,
+		"command":        command,
+		"tasks":          items,
+	})
+}
+
+func emitRecord(command, recordType, key string, value any) error {
+	if outputFormat == formatNDJSON {
+		return writeJSONLine(os.Stdout, map[string]any{
+			"schema_version": jsonSchemaVersion,
+			"type":           recordType,
+			key:              value,
+		})
+	}
+	return writeJSONDocument(map[string]any{
+		"schema_version": jsonSchemaVersion,
+		"command":        command,
+		key:              value,
+	})
+}
+
+func emitDoctor(checks []doctorCheck, status string) error {
+	if outputFormat == formatNDJSON {
+		for _, check := range checks {
+			if err := writeJSONLine(os.Stdout, struct {
+				SchemaVersion int    `json:"schema_version"`
+				Type          string `json:"type"`
+				doctorCheck
+			}{jsonSchemaVersion, "doctor_check", check}); err != nil {
+				return err
+			}
+		}
+		return writeJSONLine(os.Stdout, map[string]any{
+			"schema_version": jsonSchemaVersion,
+			"type":           "doctor_summary",
+			"status":         status,
+		})
+	}
+
+	return writeJSONDocument(map[string]any{
+		"schema_version": jsonSchemaVersion,
+		"command":        "doctor",
+		"status":         status,
+		"checks":         checks,
+	})
+}
+
+func toTemplateJSON(tmpl core.Template) templateJSON {
+	return templateJSON{
+		Name:      tmpl.Name,
+		Kind:      tmpl.Kind,
+		Title:     tmpl.Title,
+		Domain:    tmpl.Domain,
+		Tags:      nonNilStrings(tmpl.Tags),
+		Source:    tmpl.Source,
+		Overrides: tmpl.Overrides,
+		Body:      strings.TrimSpace(tmpl.Body),
+	}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func nonNilInts(values []int) []int {
+	if values == nil {
+		return []int{}
+	}
+	return values
+}
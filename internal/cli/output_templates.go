@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"nitid/internal/core"
+)
+
+type templateNote struct {
+	NoteFile
+	Note
+	Ref   string
+	Index int
+	Score int
+}
+
+type outputTemplateFlags struct {
+	inline string
+	file   string
+}
+
+func (f outputTemplateFlags) enabled() bool {
+	return strings.TrimSpace(f.inline) != "" || strings.TrimSpace(f.file) != ""
+}
+
+func (f outputTemplateFlags) load(svc *core.Service) (*template.Template, error) {
+	if strings.TrimSpace(f.inline) != "" && strings.TrimSpace(f.file) != "" {
+		return nil, errors.New("--template and --template-file cannot be combined")
+	}
+
+	text := f.inline
+	if strings.TrimSpace(f.file) != "" {
+		b, err := os.ReadFile(f.file)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	} else if !strings.Contains(text, "{{") {
+		cfg, err := svc.Config()
+		if err != nil {
+			return nil, err
+		}
+		named, ok := cfg.OutputTemplates[strings.TrimSpace(text)]
+		if !ok {
+			return nil, fmt.Errorf("unknown output template %q", strings.TrimSpace(text))
+		}
+		text = named
+	}
+
+	tmpl, err := template.New("output").Funcs(outputTemplateFuncs(time.Now().UTC())).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func outputTemplateFuncs(now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"date": func(layout string, t time.Time) string {
+			return t.In(displayLocation()).Format(layout)
+		},
+		"truncate": func(max int, value string) string {
+			return truncate(value, max)
+		},
+		"ago": func(t time.Time) string {
+			return relativeTime(t, now)
+		},
+		"join": func(sep string, values []string) string {
+			return strings.Join(values, sep)
+		},
+		"default": func(fallback, value string) string {
+			if strings.TrimSpace(value) == "" {
+				return fallback
+			}
+			return value
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+func renderOutputTemplate(tmpl *template.Template, items []templateNote) error {
+	for _, item := range items {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, item); err != nil {
+			return fmt.Errorf("render template for %s: %w", item.Note.ID, err)
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		fmt.Print(out)
+	}
+	return nil
+}
+
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix := "ago"
+	if d < 0 {
+		d = -d
+		suffix = "from now"
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %s", int(d.Minutes()), suffix)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %s", int(d.Hours()), suffix)
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd %s", int(d.Hours()/24), suffix)
+	case d < 60*24*time.Hour:
+		return fmt.Sprintf("%dw %s", int(d.Hours()/(24*7)), suffix)
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo %s", int(d.Hours()/(24*30)), suffix)
+	default:
+		return fmt.Sprintf("%dy %s", int(d.Hours()/(24*365)), suffix)
+	}
+}
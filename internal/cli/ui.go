@@ -2,7 +2,20 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"nitid/internal/core"
+)
+
+const (
+	ansiHighlight      = "\x1b[1;33m"
+	ansiReset          = "\x1b[0m"
+	maxSnippetsPerNote = 3
+	maxSnippetWidth    = 120
 )
 
 func shortID(id string) string {
@@ -14,13 +27,41 @@ func shortID(id string) string {
 
 func truncate(value string, max int) string {
 	value = strings.TrimSpace(value)
-	if len(value) <= max {
+	runes := []rune(value)
+	if len(runes) <= max {
 		return value
 	}
 	if max <= 3 {
-		return value[:max]
+		return string(runes[:max])
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+func trimPositions(value string, positions []int) (string, []int) {
+	trimmed := strings.TrimLeftFunc(value, unicode.IsSpace)
+	lead := utf8.RuneCountInString(value) - utf8.RuneCountInString(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	return trimmed, keepPositions(positions, lead, utf8.RuneCountInString(trimmed))
+}
+
+func truncatePositions(value string, positions []int, max int) (string, []int) {
+	trimmed, positions := trimPositions(value, positions)
+	display := truncate(trimmed, max)
+	limit := utf8.RuneCountInString(display)
+	if display != trimmed && max > 3 {
+		limit = max - 3
+	}
+	return display, keepPositions(positions, 0, limit)
+}
+
+func keepPositions(positions []int, shift, limit int) []int {
+	kept := make([]int, 0, len(positions))
+	for _, pos := range positions {
+		if pos -= shift; pos >= 0 && pos < limit {
+			kept = append(kept, pos)
+		}
 	}
-	return value[:max-3] + "..."
+	return kept
 }
 
 func displayDomain(domain string) string {
@@ -47,3 +88,144 @@ func displayTagsCompact(tags []string) string {
 	}
 	return fmt.Sprintf("%s,%s,+%d", tags[0], tags[1], len(tags)-2)
 }
+
+func colorEnabled() bool {
+	if strings.TrimSpace(os.Getenv("NO_COLOR")) != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return (info.Mode() & os.ModeCharDevice) != 0
+}
+
+func highlightRunes(text string, positions []int, render func(string) string) string {
+	if len(positions) == 0 {
+		return text
+	}
+
+	marked := make(map[int]struct{}, len(positions))
+	for _, pos := range positions {
+		marked[pos] = struct{}{}
+	}
+
+	runes := []rune(text)
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		if _, ok := marked[i]; !ok {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) {
+			if _, ok := marked[j]; !ok {
+				break
+			}
+			j++
+		}
+		b.WriteString(render(string(runes[i:j])))
+		i = j
+	}
+	return b.String()
+}
+
+func ansiRender(value string) string {
+	return ansiHighlight + value + ansiReset
+}
+
+func highlightTitle(title string, max int, positions []int) string {
+	display, positions := truncatePositions(title, positions, max)
+	if !colorEnabled() || len(positions) == 0 {
+		return display
+	}
+	return highlightRunes(display, positions, ansiRender)
+}
+
+func highlightMatch(text string, positions []int) string {
+	if !colorEnabled() {
+		return text
+	}
+	return highlightRunes(text, positions, ansiRender)
+}
+
+func printSnippets(body string, matches []core.LineMatch, offset, context, max int, indent string) {
+	if len(matches) == 0 {
+		return
+	}
+
+	hidden := 0
+	if max > 0 && len(matches) > max {
+		hidden = len(matches) - max
+		matches = matches[:max]
+	}
+
+	bodyLines := strings.Split(body, "\n")
+	byLine := make(map[int]core.LineMatch, len(matches))
+	visible := map[int]struct{}{}
+	for _, match := range matches {
+		byLine[match.Line] = match
+		for n := match.Line - context; n <= match.Line+context; n++ {
+			if n >= 1 && n <= len(bodyLines) {
+				visible[n] = struct{}{}
+			}
+		}
+	}
+
+	lineNumbers := make([]int, 0, len(visible))
+	for n := range visible {
+		lineNumbers = append(lineNumbers, n)
+	}
+	sort.Ints(lineNumbers)
+
+	prev := 0
+	for _, n := range lineNumbers {
+		if prev > 0 && n > prev+1 {
+			fmt.Printf("%s--\n", indent)
+		}
+		if match, ok := byLine[n]; ok {
+			fmt.Printf("%s%d: %s\n", indent, n+offset, snippetText(match.Text, match.Positions))
+		} else {
+			fmt.Printf("%s%d- %s\n", indent, n+offset, snippetText(bodyLines[n-1], nil))
+		}
+		prev = n
+	}
+
+	if hidden > 0 {
+		fmt.Printf("%s(+%d more matching lines)\n", indent, hidden)
+	}
+}
+
+func snippetText(line string, positions []int) string {
+	runes := []rune(line)
+	if len(runes) <= maxSnippetWidth {
+		return highlightMatch(line, positions)
+	}
+
+	start := 0
+	if len(positions) > 0 && positions[0] > maxSnippetWidth/3 {
+		start = positions[0] - maxSnippetWidth/3
+	}
+	end := start + maxSnippetWidth
+	if end > len(runes) {
+		end = len(runes)
+		start = end - maxSnippetWidth
+	}
+
+	shifted := make([]int, 0, len(positions))
+	for _, pos := range positions {
+		if pos >= start && pos < end {
+			shifted = append(shifted, pos-start)
+		}
+	}
+
+	text := highlightMatch(string(runes[start:end]), shifted)
+	if start > 0 {
+		text = "..." + text
+	}
+	if end < len(runes) {
+		text += "..."
+	}
+	return text
+}
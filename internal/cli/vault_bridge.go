@@ -39,6 +39,8 @@ func saveNote(root, currentPath string, note Note) (string, error) {
 func validateNoteForWrite(note Note) error { return vault.ValidateNoteForWrite(note) }
 func newULID(now time.Time) string         { return vault.NewULID(now) }
 func parseCSV(value string) []string       { return vault.ParseCSV(value) }
+func sanitizeTags(tags []string) []string  { return vault.SanitizeTags(tags) }
+func slugify(input string) string          { return vault.Slugify(input) }
 func isAllowedKind(kind string) bool {
 	return vault.IsAllowedKind(strings.ToLower(strings.TrimSpace(kind)))
 }
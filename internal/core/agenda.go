@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+type Agenda struct {
+	Today    time.Time
+	End      time.Time
+	Overdue  []Task
+	DueToday []Task
+	Upcoming []Task
+	Dailies  []NoteFile
+}
+
+func (s *Service) Agenda(today time.Time, days int) (Agenda, error) {
+	if days < 0 {
+		return Agenda{}, fmt.Errorf("agenda days must not be negative")
+	}
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	agenda := Agenda{Today: today, End: today.AddDate(0, 0, days)}
+
+	tasks, err := s.Tasks(NoteFilter{}, TaskFilter{Open: true})
+	if err != nil {
+		return Agenda{}, err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Due.Before(tasks[j].Due)
+	})
+	for _, task := range tasks {
+		if task.Due.IsZero() || task.Note.Note.Status == vault.StatusArchived {
+			continue
+		}
+		switch {
+		case task.Due.Before(today):
+			agenda.Overdue = append(agenda.Overdue, task)
+		case task.Due.Equal(today):
+			agenda.DueToday = append(agenda.DueToday, task)
+		case !task.Due.After(agenda.End):
+			agenda.Upcoming = append(agenda.Upcoming, task)
+		}
+	}
+
+	dailies, err := s.List(NoteFilter{Kind: "daily"}, "created", true)
+	if err != nil {
+		return Agenda{}, err
+	}
+	for _, item := range dailies {
+		day := item.Note.CreatedAt.Format("2006-01-02")
+		if day >= today.Format("2006-01-02") && day <= agenda.End.Format("2006-01-02") {
+			agenda.Dailies = append(agenda.Dailies, item)
+		}
+	}
+	return agenda, nil
+}
+
+func (a Agenda) ICS(now time.Time) string {
+	var b strings.Builder
+	writeLine := func(line string) {
+		for len(line) > 75 {
+			cut := 75
+			for cut > 0 && !isICSBoundary(line, cut) {
+				cut--
+			}
+			b.WriteString(line[:cut] + "\r\n")
+			line = " " + line[cut:]
+		}
+		b.WriteString(line + "\r\n")
+	}
+	writeEvent := func(uid string, day time.Time, summary, description string) {
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + uid)
+		writeLine("DTSTAMP:" + now.UTC().Format("20060102T150405Z"))
+		writeLine("DTSTART;VALUE=DATE:" + day.Format("20060102"))
+		writeLine("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format("20060102"))
+		writeLine("SUMMARY:" + escapeICSText(summary))
+		writeLine("DESCRIPTION:" + escapeICSText(description))
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//nitid//ntd agenda//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	uids := map[string]int{}
+	for _, group := range [][]Task{a.Overdue, a.DueToday, a.Upcoming} {
+		for _, task := range group {
+			description := task.Note.Note.Title
+			if task.Heading != "" {
+				description += " > " + task.Heading
+			}
+			uid := task.UID()
+			if uids[uid]++; uids[uid] > 1 {
+				uid = fmt.Sprintf("%s-%d", uid, uids[uid])
+			}
+			writeEvent(uid+"@nitid", task.Due, task.Text, description+"\n"+task.Ref(""))
+		}
+	}
+	for _, item := range a.Dailies {
+		day := item.Note.CreatedAt.UTC()
+		writeEvent(item.Note.ID+"@nitid", time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC), item.Note.Title, item.RelPath)
+	}
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+func isICSBoundary(line string, idx int) bool {
+	return idx >= len(line) || line[idx]&0xC0 != 0x80
+}
+
+func escapeICSText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
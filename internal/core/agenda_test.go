@@ -0,0 +1,102 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+func TestAgendaGroups(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	svc, _ := newTestService(t,
+		Note{Title: "Plan", Status: vault.StatusInbox, CreatedAt: created, Body: "- [ ] Late due:2026-03-04\n- [ ] Now due:2026-03-05\n- [ ] Soon due:2026-03-12\n- [ ] Far due:2026-03-13\n- [x] Done due:2026-03-05\n- [ ] Someday"},
+		Note{Title: "Old", Status: vault.StatusArchived, CreatedAt: created.Add(time.Minute), Body: "- [ ] Archived due:2026-03-01"},
+		Note{Title: "Daily 2026-03-06", Kind: "daily", Status: vault.StatusActive, CreatedAt: time.Date(2026, 3, 6, 9, 0, 0, 0, time.UTC)},
+		Note{Title: "Daily 2026-03-02", Kind: "daily", Status: vault.StatusActive, CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+	)
+
+	agenda, err := svc.Agenda(time.Date(2026, 3, 5, 18, 0, 0, 0, time.UTC), 7)
+	if err != nil {
+		t.Fatalf("agenda: %v", err)
+	}
+	texts := func(tasks []Task) string {
+		out := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			out = append(out, task.Text)
+		}
+		return strings.Join(out, "|")
+	}
+	if got := texts(agenda.Overdue); got != "Late due:2026-03-04" {
+		t.Fatalf("overdue: %s", got)
+	}
+	if got := texts(agenda.DueToday); got != "Now due:2026-03-05" {
+		t.Fatalf("today: %s", got)
+	}
+	if got := texts(agenda.Upcoming); got != "Soon due:2026-03-12" {
+		t.Fatalf("upcoming: %s", got)
+	}
+	if len(agenda.Dailies) != 1 || agenda.Dailies[0].Note.Title != "Daily 2026-03-06" {
+		t.Fatalf("dailies: %+v", agenda.Dailies)
+	}
+
+	ics := agenda.ICS(time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC))
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART;VALUE=DATE:20260304\r\nDTEND;VALUE=DATE:20260305\r\n",
+		"SUMMARY:Now due:2026-03-05\r\n",
+		"SUMMARY:Daily 2026-03-06\r\n",
+		"DTSTAMP:20260305T120000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Fatalf("ics missing %q:\n%s", want, ics)
+		}
+	}
+	if strings.Count(ics, "BEGIN:VEVENT") != 4 {
+		t.Fatalf("expected 4 events:\n%s", ics)
+	}
+}
+
+func TestAgendaICSEscapesAndFolds(t *testing.T) {
+	task := Task{
+		Note: NoteFile{Note: Note{ID: "01JNA0000000000000000000T1", Title: "Plan; v2"}},
+		Line: 3,
+		Text: "Review, merge, and ship " + strings.Repeat("é", 40),
+		Due:  time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC),
+	}
+	ics := Agenda{DueToday: []Task{task}}.ICS(time.Now())
+	if !strings.Contains(ics, `SUMMARY:Review\, merge\, and ship`) || !strings.Contains(ics, `DESCRIPTION:Plan\; v2\n01JNA0000000000000000000T1:3`) {
+		t.Fatalf("ics not escaped:\n%s", ics)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line longer than 75 octets: %q", line)
+		}
+	}
+	if !strings.Contains(ics, "\r\n é") {
+		t.Fatalf("expected folded continuation line:\n%s", ics)
+	}
+}
+
+func TestAgendaICSStableUIDs(t *testing.T) {
+	note := NoteFile{Note: Note{ID: "01JNA0000000000000000000T1", Title: "Plan"}}
+	due := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	base := Task{Note: note, Line: 3, Text: "Ship release due:2026-03-05", Due: due}
+	moved := Task{Note: note, Line: 9, Text: "Ship  release @ana !high due:2026-03-12", Due: due}
+	if base.UID() != moved.UID() {
+		t.Fatalf("uid changed with line and tags: %s vs %s", base.UID(), moved.UID())
+	}
+	if other := (Task{Note: note, Line: 3, Text: "Ship docs due:2026-03-05"}); other.UID() == base.UID() {
+		t.Fatalf("different tasks share uid %s", base.UID())
+	}
+	if !strings.HasPrefix(base.UID(), "01JNA0000000000000000000T1-") || len(base.UID()) != len("01JNA0000000000000000000T1-")+16 {
+		t.Fatalf("unexpected uid %s", base.UID())
+	}
+
+	ics := Agenda{DueToday: []Task{base, moved}}.ICS(time.Now())
+	if !strings.Contains(ics, "UID:"+base.UID()+"@nitid\r\n") || !strings.Contains(ics, "UID:"+base.UID()+"-2@nitid\r\n") {
+		t.Fatalf("expected deduplicated uids:\n%s", ics)
+	}
+}
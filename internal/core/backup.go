@@ -0,0 +1,391 @@
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+const (
+	backupManifestName    = "manifest.json"
+	backupManifestVersion = 1
+)
+
+type BackupFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type BackupManifest struct {
+	ManifestVersion int          `json:"manifest_version"`
+	SchemaVersion   int          `json:"schema_version"`
+	CreatedAt       time.Time    `json:"created_at"`
+	Files           []BackupFile `json:"files"`
+}
+
+type BackupResult struct {
+	Path     string
+	Manifest BackupManifest
+}
+
+type BackupCheck struct {
+	Path     string
+	Manifest BackupManifest
+	Problems []string
+}
+
+type BackupRecord struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     int       `json:"files"`
+}
+
+type RestoreResult struct {
+	Dir      string
+	Manifest BackupManifest
+}
+
+func DefaultBackupPath(root string, now time.Time) string {
+	name := fmt.Sprintf("nitid-backup-%s.tar.gz", now.UTC().Format("20060102T150405Z"))
+	return filepath.Join(root, ".nitid", "backups", name)
+}
+
+func (s *Service) Backup(out string, now time.Time) (BackupResult, error) {
+	result := BackupResult{Path: out}
+	cfg, err := s.Config()
+	if err != nil {
+		return result, err
+	}
+	files, err := s.backupSources()
+	if err != nil {
+		return result, err
+	}
+	if len(files) == 0 {
+		return result, errors.New("nothing to back up: no notes, assets, or config found")
+	}
+
+	manifest := BackupManifest{
+		ManifestVersion: backupManifestVersion,
+		SchemaVersion:   cfg.Version,
+		CreatedAt:       now.UTC().Truncate(time.Second),
+		Files:           make([]BackupFile, 0, len(files)),
+	}
+	for _, rel := range files {
+		full := filepath.Join(s.root, filepath.FromSlash(rel))
+		info, err := os.Stat(full)
+		if err != nil {
+			return result, err
+		}
+		hash, err := fileSHA256(full)
+		if err != nil {
+			return result, err
+		}
+		manifest.Files = append(manifest.Files, BackupFile{Path: rel, Size: info.Size(), SHA256: hash})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return result, err
+	}
+	tmp := out + ".tmp"
+	if err := writeBackupArchive(tmp, s.root, manifest); err != nil {
+		os.Remove(tmp)
+		return result, err
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		os.Remove(tmp)
+		return result, err
+	}
+
+	abs, err := filepath.Abs(out)
+	if err != nil {
+		return result, err
+	}
+	record := BackupRecord{Path: abs, CreatedAt: manifest.CreatedAt, Files: len(manifest.Files)}
+	if err := s.writeBackupRecord(record); err != nil {
+		return result, err
+	}
+
+	result.Manifest = manifest
+	return result, nil
+}
+
+func (s *Service) LastBackup() (BackupRecord, bool, error) {
+	b, err := os.ReadFile(s.backupRecordPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return BackupRecord{}, false, nil
+	}
+	if err != nil {
+		return BackupRecord{}, false, err
+	}
+	var record BackupRecord
+	if err := json.Unmarshal(b, &record); err != nil {
+		return BackupRecord{}, false, fmt.Errorf("parse %s: %w", filepath.ToSlash(filepath.Join(".nitid", "backup.json")), err)
+	}
+	return record, true, nil
+}
+
+func VerifyBackup(file string) (BackupCheck, error) {
+	check := BackupCheck{Path: file}
+	hashes := map[string]string{}
+	var manifestData []byte
+
+	err := readBackupArchive(file, func(name string, r io.Reader) error {
+		if name == backupManifestName {
+			data, err := io.ReadAll(r)
+			manifestData = data
+			return err
+		}
+		hash := sha256.New()
+		if _, err := io.Copy(hash, r); err != nil {
+			return err
+		}
+		hashes[name] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return check, err
+	}
+	if manifestData == nil {
+		return check, fmt.Errorf("%s is not a nitid backup: %s is missing", file, backupManifestName)
+	}
+	if err := json.Unmarshal(manifestData, &check.Manifest); err != nil {
+		return check, fmt.Errorf("parse %s: %w", backupManifestName, err)
+	}
+	if check.Manifest.ManifestVersion > backupManifestVersion {
+		check.Problems = append(check.Problems, fmt.Sprintf("unsupported manifest version %d", check.Manifest.ManifestVersion))
+	}
+
+	listed := map[string]bool{}
+	for _, entry := range check.Manifest.Files {
+		listed[entry.Path] = true
+		hash, ok := hashes[entry.Path]
+		switch {
+		case !safeBackupPath(entry.Path):
+			check.Problems = append(check.Problems, fmt.Sprintf("unsafe path in manifest: %s", entry.Path))
+		case !ok:
+			check.Problems = append(check.Problems, fmt.Sprintf("missing from archive: %s", entry.Path))
+		case hash != entry.SHA256:
+			check.Problems = append(check.Problems, fmt.Sprintf("checksum mismatch: %s", entry.Path))
+		}
+	}
+	for name := range hashes {
+		if !listed[name] {
+			check.Problems = append(check.Problems, fmt.Sprintf("not in manifest: %s", name))
+		}
+	}
+	sort.Strings(check.Problems)
+	return check, nil
+}
+
+func RestoreBackup(file, dir string) (RestoreResult, error) {
+	result := RestoreResult{Dir: dir}
+	check, err := VerifyBackup(file)
+	if err != nil {
+		return result, err
+	}
+	if len(check.Problems) > 0 {
+		return result, fmt.Errorf("backup %s failed verification: %s", file, check.Problems[0])
+	}
+	result.Manifest = check.Manifest
+
+	entries, err := os.ReadDir(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return result, err
+	case len(entries) > 0:
+		return result, fmt.Errorf("restore target %s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return result, err
+	}
+
+	err = readBackupArchive(file, func(name string, r io.Reader) error {
+		if name == backupManifestName {
+			return nil
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+	if err != nil {
+		return result, err
+	}
+	if err := vault.CreateVaultStructure(dir); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (s *Service) backupSources() ([]string, error) {
+	files := make([]string, 0)
+	for _, dir := range []string{"notes", "assets"} {
+		base := filepath.Join(s.root, dir)
+		if _, err := os.Stat(base); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		err := filepath.WalkDir(base, func(p string, d os.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(s.root, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	config := filepath.Join(".nitid", "config.toml")
+	if _, err := os.Stat(filepath.Join(s.root, config)); err == nil {
+		files = append(files, filepath.ToSlash(config))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (s *Service) backupRecordPath() string {
+	return filepath.Join(s.root, ".nitid", "backup.json")
+}
+
+func (s *Service) writeBackupRecord(record BackupRecord) error {
+	b, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.backupRecordPath()), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.backupRecordPath(), append(b, '\n'), 0o644)
+}
+
+func writeBackupArchive(out, root string, manifest BackupManifest) error {
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: backupManifestName, Mode: 0o644, Size: int64(len(manifestData)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestData); err != nil {
+		return err
+	}
+
+	for _, entry := range manifest.Files {
+		if err := addBackupFile(tw, root, entry); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func addBackupFile(tw *tar.Writer, root string, entry BackupFile) error {
+	full := filepath.Join(root, filepath.FromSlash(entry.Path))
+	file, err := os.Open(full)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != entry.Size {
+		return fmt.Errorf("%s changed during backup", entry.Path)
+	}
+	header := &tar.Header{Name: entry.Path, Mode: 0o644, Size: entry.Size, ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, file, entry.Size)
+	return err
+}
+
+func readBackupArchive(file string, visit func(name string, r io.Reader) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s is not a gzip archive: %w", file, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unexpected entry type in backup: %s", header.Name)
+		}
+		if !safeBackupPath(header.Name) {
+			return fmt.Errorf("unsafe path in backup: %s", header.Name)
+		}
+		if err := visit(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func safeBackupPath(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return false
+	}
+	clean := path.Clean(name)
+	return clean == name && clean != ".." && !strings.HasPrefix(clean, "../")
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVerifyBackupReportsProblems(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes", "a.md"), []byte("alpha"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes", "b.md"), []byte("beta"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := BackupManifest{
+		ManifestVersion: backupManifestVersion,
+		SchemaVersion:   1,
+		CreatedAt:       time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Files: []BackupFile{
+			{Path: "notes/a.md", Size: 5, SHA256: "0000"},
+			{Path: "notes/b.md", Size: 4, SHA256: "1111"},
+		},
+	}
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if err := writeBackupArchive(archive, root, manifest); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+
+	check, err := VerifyBackup(archive)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	want := []string{"checksum mismatch: notes/a.md", "checksum mismatch: notes/b.md"}
+	if !reflect.DeepEqual(check.Problems, want) {
+		t.Fatalf("expected %v, got %v", want, check.Problems)
+	}
+	if _, err := RestoreBackup(archive, filepath.Join(t.TempDir(), "out")); err == nil {
+		t.Fatalf("restore should refuse an archive that fails verification")
+	}
+}
+
+func TestSafeBackupPath(t *testing.T) {
+	cases := map[string]bool{
+		"notes/a.md":         true,
+		".nitid/config.toml": true,
+		"../etc/passwd":      false,
+		"/etc/passwd":        false,
+		"notes/../../x":      false,
+		"notes//a.md":        false,
+		"":                   false,
+	}
+	for name, want := range cases {
+		if got := safeBackupPath(name); got != want {
+			t.Errorf("safeBackupPath(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+const (
+	CarryOverCopy = "copy"
+	CarryOverMove = "move"
+	CarryOverOff  = "off"
+)
+
+var openTaskPattern = regexp.MustCompile(`^\s*[-*+] \[ \]\s+(\S.*)$`)
+
+var dailyPlaceholderTasks = map[string]bool{
+	"Top priority 1": true,
+	"Top priority 2": true,
+}
+
+type DailyCarryOver struct {
+	Source NoteFile
+	Tasks  []string
+	lines  []int
+}
+
+func IsCarryOverPolicy(value string) bool {
+	return value == CarryOverCopy || value == CarryOverMove || value == CarryOverOff
+}
+
+func (s *Service) FindPreviousDaily(date time.Time) (NoteFile, bool, error) {
+	notes, err := vault.ListNotes(s.root, NoteFilter{Kind: "daily"})
+	if err != nil {
+		return NoteFile{}, false, err
+	}
+
+	target := date.Format("2006-01-02")
+	var previous NoteFile
+	found := false
+	for _, item := range notes {
+		day := item.Note.CreatedAt.Format("2006-01-02")
+		if day >= target {
+			continue
+		}
+		if !found || day > previous.Note.CreatedAt.Format("2006-01-02") {
+			previous = item
+			found = true
+		}
+	}
+	return previous, found, nil
+}
+
+func (s *Service) DailyCarryOver(date time.Time) (DailyCarryOver, bool, error) {
+	previous, found, err := s.FindPreviousDaily(date)
+	if err != nil || !found {
+		return DailyCarryOver{}, false, err
+	}
+
+	carry := DailyCarryOver{Source: previous}
+	inFence := false
+	for i, line := range strings.Split(previous.Note.Body, "\n") {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		match := openTaskPattern.FindStringSubmatch(line)
+		if match == nil || dailyPlaceholderTasks[strings.TrimSpace(match[1])] {
+			continue
+		}
+		carry.Tasks = append(carry.Tasks, ResolveDue(strings.TrimSpace(match[1]), previous.Note.CreatedAt))
+		carry.lines = append(carry.lines, i)
+	}
+	if len(carry.Tasks) == 0 {
+		return DailyCarryOver{}, false, nil
+	}
+	return carry, true, nil
+}
+
+func (s *Service) RemoveCarriedTasks(carry DailyCarryOver, now time.Time) (MutationResult, error) {
+	current, err := vault.ReadNote(carry.Source.Path)
+	if err != nil {
+		return MutationResult{}, err
+	}
+	if current.Body != carry.Source.Note.Body {
+		return MutationResult{}, fmt.Errorf("%s changed while carrying tasks over", carry.Source.RelPath)
+	}
+
+	drop := make(map[int]bool, len(carry.lines))
+	for _, line := range carry.lines {
+		drop[line] = true
+	}
+	kept := make([]string, 0)
+	for i, line := range strings.Split(current.Body, "\n") {
+		if !drop[i] {
+			kept = append(kept, line)
+		}
+	}
+	current.Body = strings.TrimSpace(strings.Join(kept, "\n"))
+	current.UpdatedAt = now.UTC()
+
+	rel, err := vault.SaveNote(s.root, carry.Source.Path, current)
+	if err != nil {
+		return MutationResult{}, err
+	}
+	return MutationResult{NoteID: current.ID, RelPath: rel}, nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	dateOffsetPattern  = regexp.MustCompile(`^([+-])(\d+)([dw])$`)
+	dateISOWeekPattern = regexp.MustCompile(`^(\d{4})-w(\d{2})$`)
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+func ParseDate(expr string, today time.Time) (time.Time, error) {
+	value := strings.ToLower(strings.Join(strings.Fields(expr), " "))
+	for _, prefix := range []string{"last-", "next-"} {
+		if strings.HasPrefix(value, prefix) {
+			value = strings.Replace(value, "-", " ", 1)
+		}
+	}
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch value {
+	case "today":
+		return day, nil
+	case "yesterday":
+		return day.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return day.AddDate(0, 0, 1), nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	if m := dateOffsetPattern.FindStringSubmatch(value); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err == nil && n <= 36500 {
+			if m[3] == "w" {
+				n *= 7
+			}
+			if m[1] == "-" {
+				n = -n
+			}
+			return day.AddDate(0, 0, n), nil
+		}
+	}
+
+	if m := dateISOWeekPattern.FindStringSubmatch(value); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+		monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+		if y, w := monday.ISOWeek(); week >= 1 && y == year && w == week {
+			return monday, nil
+		}
+	}
+
+	modifier, name, found := strings.Cut(value, " ")
+	if !found {
+		modifier, name = "", value
+	}
+	if weekday, ok := weekdayNames[name]; ok {
+		diff := (int(weekday) - int(day.Weekday()) + 7) % 7
+		switch modifier {
+		case "":
+			return day.AddDate(0, 0, diff), nil
+		case "next":
+			if diff == 0 {
+				diff = 7
+			}
+			return day.AddDate(0, 0, diff), nil
+		case "last":
+			back := (int(day.Weekday()) - int(weekday) + 7) % 7
+			if back == 0 {
+				back = 7
+			}
+			return day.AddDate(0, 0, -back), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD, today, yesterday, tomorrow, [last|next] <weekday>, -3d, +2w, or YYYY-Www", strings.TrimSpace(expr))
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	today := time.Date(2026, 10, 14, 22, 30, 0, 0, time.FixedZone("PST", -8*3600))
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "today", want: "2026-10-14"},
+		{input: " Today ", want: "2026-10-14"},
+		{input: "yesterday", want: "2026-10-13"},
+		{input: "tomorrow", want: "2026-10-15"},
+		{input: "2026-02-28", want: "2026-02-28"},
+		{input: "-3d", want: "2026-10-11"},
+		{input: "+3d", want: "2026-10-17"},
+		{input: "-2w", want: "2026-09-30"},
+		{input: "+1w", want: "2026-10-21"},
+		{input: "friday", want: "2026-10-16"},
+		{input: "wednesday", want: "2026-10-14"},
+		{input: "mon", want: "2026-10-19"},
+		{input: "next monday", want: "2026-10-19"},
+		{input: "next wednesday", want: "2026-10-21"},
+		{input: "next-friday", want: "2026-10-16"},
+		{input: "last friday", want: "2026-10-09"},
+		{input: "last  Tuesday", want: "2026-10-13"},
+		{input: "last wednesday", want: "2026-10-07"},
+		{input: "last-sun", want: "2026-10-11"},
+		{input: "2026-W42", want: "2026-10-12"},
+		{input: "2026-w01", want: "2025-12-29"},
+		{input: "2026-W53", want: "2026-12-28"},
+		{input: "2027-W53", wantErr: true},
+		{input: "2020-W53", want: "2020-12-28"},
+		{input: "2026-W00", wantErr: true},
+		{input: "3d", wantErr: true},
+		{input: "next week", wantErr: true},
+		{input: "someday", wantErr: true},
+		{input: "2026-13-01", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input, today)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", got.Format("2006-01-02"))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got.Format("2006-01-02") != tt.want || got.Location() != time.UTC || got.Hour() != 0 {
+				t.Fatalf("got %s, want %s at midnight UTC", got, tt.want)
+			}
+		})
+	}
+}
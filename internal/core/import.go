@@ -0,0 +1,455 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"nitid/internal/vault"
+)
+
+var hashtagPattern = regexp.MustCompile(`(?:^|[\s(\[,;])#([\p{L}][\p{L}\p{N}_/-]*)`)
+
+type ImportOptions struct {
+	DryRun bool
+	Domain string
+}
+
+type ImportItem struct {
+	Source   string
+	NoteID   string
+	Title    string
+	RelPath  string
+	Domain   string
+	Tags     []string
+	Reason   string
+	Replaced bool
+}
+
+type ImportAsset struct {
+	Source string
+	Target string
+	path   string
+	data   []byte
+}
+
+type ImportReport struct {
+	Source   string
+	DryRun   bool
+	Imported []ImportItem
+	Skipped  []ImportItem
+	Assets   []ImportAsset
+	Warnings []string
+}
+
+type assetNamer struct {
+	hashes map[string]string
+}
+
+type importCandidate struct {
+	source string
+	note   Note
+	assets []ImportAsset
+}
+
+func (s *Service) ImportMarkdown(dir string, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{Source: dir, DryRun: opts.DryRun}
+	if opts.Domain != "" && !vault.IsValidDomainID(opts.Domain) {
+		return report, fmt.Errorf("invalid domain %q: use lowercase kebab-case", opts.Domain)
+	}
+
+	if err := s.checkImportSource(dir); err != nil {
+		return report, err
+	}
+	files, err := collectMarkdownFiles(dir)
+	if err != nil {
+		return report, err
+	}
+
+	candidates := make([]importCandidate, 0, len(files))
+	folderDomains := map[string]string{}
+	for _, rel := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return report, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return report, err
+		}
+
+		properties, body, err := splitOptionalFrontmatter(content)
+		if err != nil {
+			report.Skipped = append(report.Skipped, ImportItem{Source: rel, Reason: err.Error()})
+			continue
+		}
+		body = strings.TrimSpace(body)
+		if body == "" && len(properties) == 0 {
+			report.Skipped = append(report.Skipped, ImportItem{Source: rel, Reason: "empty file"})
+			continue
+		}
+
+		modTime := info.ModTime().UTC().Truncate(time.Second)
+		note := Note{
+			ID:        vault.NewULID(modTime),
+			Title:     importTitle(body, rel),
+			CreatedAt: modTime,
+			UpdatedAt: modTime,
+			Domain:    opts.Domain,
+			Tags:      ExtractHashtags(body),
+			Kind:      "note",
+			Links:     []string{},
+			Body:      body,
+		}
+
+		dirs := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+		if dirs[0] != "." {
+			domain, seen := folderDomains[dirs[0]]
+			if !seen {
+				var ok bool
+				domain, ok = folderDomain(dirs[0])
+				if !ok {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("%s/: folder name has no ASCII letters or digits, imported into domain %q; rename the folder or import it on its own with --domain", dirs[0], domain))
+				}
+				folderDomains[dirs[0]] = domain
+			}
+			note.Domain = domain
+			for _, sub := range dirs[1:] {
+				if tag := vault.NormalizeTag(sub); tag != "" {
+					note.Tags = append(note.Tags, tag)
+				}
+			}
+		}
+
+		if title, ok := properties["title"].(string); ok && strings.TrimSpace(title) != "" {
+			note.Title = strings.TrimSpace(title)
+		}
+		note.Tags = append(note.Tags, propertyTags(properties["tags"])...)
+		note.Tags = vault.SanitizeTags(note.Tags)
+		for key := range properties {
+			if key != "title" && key != "tags" {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: frontmatter property %q was not imported", rel, key))
+			}
+		}
+
+		candidates = append(candidates, importCandidate{source: rel, note: note})
+	}
+
+	if err := s.writeImported(candidates, &report); err != nil {
+		return report, err
+	}
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+func (s *Service) writeImported(candidates []importCandidate, report *ImportReport) error {
+	existing, err := vault.ListNotes(s.root, NoteFilter{})
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, item := range existing {
+		seen[importFingerprint(item.Note)] = true
+	}
+
+	if !report.DryRun {
+		if err := s.Init(); err != nil {
+			return err
+		}
+	}
+
+	copied := map[string]bool{}
+	reported := map[string]bool{}
+
+	for _, candidate := range candidates {
+		note := candidate.note
+		if note.Status == "" {
+			note.Status = vault.StatusActive
+			if note.Domain == "" && !vault.IsPeriodicKind(note.Kind) {
+				note.Status = vault.StatusInbox
+			}
+		}
+		item := ImportItem{
+			Source: candidate.source,
+			NoteID: note.ID,
+			Title:  note.Title,
+			Domain: note.Domain,
+			Tags:   note.Tags,
+		}
+
+		if seen[importFingerprint(note)] {
+			item.Reason = "already imported"
+			report.Skipped = append(report.Skipped, item)
+			continue
+		}
+		if err := vault.ValidateNoteForWrite(note); err != nil {
+			item.Reason = err.Error()
+			report.Skipped = append(report.Skipped, item)
+			continue
+		}
+		seen[importFingerprint(note)] = true
+
+		if report.DryRun {
+			path, err := vault.ResolveNotePath(s.root, note)
+			if err != nil {
+				return err
+			}
+			item.RelPath = toRelOrAbs(s.root, path)
+		} else {
+			for _, asset := range candidate.assets {
+				if copied[asset.Target] {
+					continue
+				}
+				if err := writeImportAsset(asset, filepath.Join(s.root, filepath.FromSlash(asset.Target))); err != nil {
+					return err
+				}
+				copied[asset.Target] = true
+			}
+			relPath, err := vault.WriteNote(s.root, note)
+			if err != nil {
+				return err
+			}
+			item.RelPath = relPath
+		}
+		for _, asset := range candidate.assets {
+			if !reported[asset.Target] {
+				reported[asset.Target] = true
+				report.Assets = append(report.Assets, asset)
+			}
+		}
+		report.Imported = append(report.Imported, item)
+	}
+	return nil
+}
+
+func writeImportAsset(asset ImportAsset, target string) error {
+	if asset.data == nil {
+		return copyFile(asset.path, target)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, asset.data, 0o644)
+}
+
+func folderDomain(name string) (string, bool) {
+	domain := vault.Slugify(name)
+	if domain != "untitled" || strings.EqualFold(strings.TrimSpace(name), "untitled") {
+		return domain, true
+	}
+	sum := sha256.Sum256([]byte(name))
+	return "untitled-" + hex.EncodeToString(sum[:3]), false
+}
+
+func (s *Service) newAssetNamer() (*assetNamer, error) {
+	namer := &assetNamer{hashes: map[string]string{}}
+	assetsDir := filepath.Join(s.root, "assets")
+	entries, err := os.ReadDir(assetsDir)
+	if os.IsNotExist(err) {
+		return namer, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		hash, err := fileSHA256(filepath.Join(assetsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		namer.hashes[entry.Name()] = hash
+	}
+	return namer, nil
+}
+
+func (n *assetNamer) name(base, hash string) string {
+	base = strings.TrimSpace(strings.NewReplacer("/", "-", "\\", "-").Replace(base))
+	if base == "" || base == "." {
+		base = "attachment"
+	}
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	name := base
+	for i := 1; ; i++ {
+		existing, taken := n.hashes[name]
+		if !taken || existing == hash {
+			break
+		}
+		name = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	n.hashes[name] = hash
+	return name
+}
+
+func (s *Service) checkImportSource(source string) error {
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(s.root, abs)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == "notes" || strings.HasPrefix(rel, "notes/") {
+		return fmt.Errorf("cannot import from %s: it is part of this vault", source)
+	}
+	return nil
+}
+
+func importFingerprint(note Note) string {
+	return strings.Join([]string{
+		strings.ToLower(strings.TrimSpace(note.Title)),
+		note.Kind,
+		note.Domain,
+		note.CreatedAt.UTC().Format(time.RFC3339),
+	}, "\x00")
+}
+
+func importTitle(body, rel string) string {
+	if heading := FirstHeading(body); heading != "" {
+		return heading
+	}
+	stem := strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	stem = strings.TrimSpace(strings.NewReplacer("_", " ", "-", " ").Replace(stem))
+	if stem != "" {
+		return stem
+	}
+	return InferTitle(body)
+}
+
+func ExtractHashtags(body string) []string {
+	tags := make([]string, 0)
+	for _, raw := range rawHashtags(body) {
+		if tag := vault.NormalizeTag(raw); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return vault.SanitizeTags(tags)
+}
+
+func rawHashtags(body string) []string {
+	tags := make([]string, 0)
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		line = stripInlineCode(line)
+		for _, match := range hashtagPattern.FindAllStringSubmatch(line, -1) {
+			tags = append(tags, strings.TrimRight(match[1], "/"))
+		}
+	}
+	return tags
+}
+
+func stripInlineCode(line string) string {
+	var b strings.Builder
+	inCode := false
+	for _, r := range line {
+		if r == '`' {
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func propertyTags(value any) []string {
+	tags := make([]string, 0)
+	for _, item := range propertyStrings(value) {
+		if tag := vault.NormalizeTag(item); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func splitOptionalFrontmatter(content []byte) (map[string]any, string, error) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, text, nil
+	}
+	end := strings.Index(text[4:], "\n---")
+	if end < 0 {
+		return nil, text, nil
+	}
+	raw := text[4 : 4+end]
+	rest := text[4+end+4:]
+	if idx := strings.IndexByte(rest, '\n'); idx >= 0 {
+		rest = rest[idx+1:]
+	} else {
+		rest = ""
+	}
+
+	var properties map[string]any
+	if err := yaml.Unmarshal([]byte(raw), &properties); err != nil {
+		return nil, "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	return properties, rest, nil
+}
+
+func collectMarkdownFiles(dir string) ([]string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	files := make([]string, 0)
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(d.Name()))
+		if ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no markdown files found")
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,685 @@
+package core
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+const enexTimeLayout = "20060102T150405Z"
+
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+type EnexOptions struct {
+	DryRun bool
+	Domain string
+}
+
+type enexNote struct {
+	Title      string   `xml:"title"`
+	Content    string   `xml:"content"`
+	Created    string   `xml:"created"`
+	Updated    string   `xml:"updated"`
+	Tags       []string `xml:"tag"`
+	Attributes struct {
+		SourceURL string `xml:"source-url"`
+	} `xml:"note-attributes"`
+	Resources []enexResource `xml:"resource"`
+}
+
+type enexResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+	Mime     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+type enexMedia struct {
+	name  string
+	mime  string
+	data  []byte
+	asset string
+}
+
+func (s *Service) ImportEnex(files []string, opts EnexOptions) (ImportReport, error) {
+	report := ImportReport{Source: strings.Join(files, ", "), DryRun: opts.DryRun}
+	if len(files) == 0 {
+		return report, errors.New("at least one .enex file is required")
+	}
+	if opts.Domain != "" && !vault.IsValidDomainID(opts.Domain) {
+		return report, fmt.Errorf("invalid domain %q: use lowercase kebab-case", opts.Domain)
+	}
+
+	namer, err := s.newAssetNamer()
+	if err != nil {
+		return report, err
+	}
+
+	candidates := make([]importCandidate, 0)
+	for _, file := range files {
+		domain := opts.Domain
+		if domain == "" {
+			domain = vault.Slugify(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+		}
+		notes, err := readEnex(file)
+		if err != nil {
+			return report, err
+		}
+		for i, raw := range notes {
+			source := fmt.Sprintf("%s#%d", filepath.Base(file), i+1)
+			candidate, warnings := convertEnexNote(raw, source, domain, namer)
+			report.Warnings = append(report.Warnings, warnings...)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if err := s.writeImported(candidates, &report); err != nil {
+		return report, err
+	}
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+func readEnex(path string) ([]enexNote, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := xml.NewDecoder(file)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	notes := make([]enexNote, 0)
+	sawExport := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "en-export":
+			sawExport = true
+		case "note":
+			var note enexNote
+			if err := decoder.DecodeElement(&note, &start); err != nil {
+				return nil, fmt.Errorf("parse %s: %w", path, err)
+			}
+			notes = append(notes, note)
+		}
+	}
+	if !sawExport {
+		return nil, fmt.Errorf("%s is not an Evernote export (missing <en-export>)", path)
+	}
+	return notes, nil
+}
+
+func convertEnexNote(raw enexNote, source, domain string, namer *assetNamer) (importCandidate, []string) {
+	warnings := make([]string, 0)
+	warn := func(message string) {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", source, message))
+	}
+
+	created, err := time.Parse(enexTimeLayout, strings.TrimSpace(raw.Created))
+	if err != nil {
+		created = time.Now().UTC().Truncate(time.Second)
+		warn("missing or invalid created time, using now")
+	}
+	updated, err := time.Parse(enexTimeLayout, strings.TrimSpace(raw.Updated))
+	if err != nil || updated.Before(created) {
+		updated = created
+	}
+
+	media := map[string]*enexMedia{}
+	for i, resource := range raw.Resources {
+		data, err := decodeEnexData(resource)
+		if err != nil {
+			warn(fmt.Sprintf("resource %d: %v", i+1, err))
+			continue
+		}
+		sum := md5.Sum(data)
+		name := strings.TrimSpace(resource.FileName)
+		if name == "" {
+			name = fmt.Sprintf("evernote-%x%s", sum[:4], mimeExtension(resource.Mime))
+		}
+		media[fmt.Sprintf("%x", sum)] = &enexMedia{name: filepath.Base(name), mime: resource.Mime, data: data}
+	}
+
+	candidate := importCandidate{source: source}
+	converter := enmlConverter{
+		media: func(hash string) (*enexMedia, bool) {
+			item, ok := media[strings.ToLower(hash)]
+			if !ok {
+				return nil, false
+			}
+			if item.asset == "" {
+				shaSum := sha256.Sum256(item.data)
+				item.asset = "assets/" + namer.name(item.name, fmt.Sprintf("%x", shaSum))
+				candidate.assets = append(candidate.assets, ImportAsset{Source: source + "/" + item.name, Target: item.asset, data: item.data})
+			}
+			return item, true
+		},
+		warn: warn,
+	}
+	body := converter.convert(raw.Content)
+
+	tags := make([]string, 0, len(raw.Tags))
+	for _, tag := range raw.Tags {
+		if normalized := vault.NormalizeTag(tag); normalized != "" {
+			tags = append(tags, normalized)
+		} else {
+			warn(fmt.Sprintf("tag %q could not be converted", tag))
+		}
+	}
+
+	title := strings.TrimSpace(raw.Title)
+	if title == "" {
+		title = InferTitle(body)
+	}
+	if body == "" {
+		body = title
+	}
+
+	links := []string{}
+	if url := strings.TrimSpace(raw.Attributes.SourceURL); url != "" {
+		links = append(links, url)
+	}
+
+	candidate.note = Note{
+		ID:        vault.NewULID(created),
+		Title:     title,
+		CreatedAt: created.UTC(),
+		UpdatedAt: updated.UTC(),
+		Domain:    domain,
+		Tags:      vault.SanitizeTags(tags),
+		Kind:      "note",
+		Links:     links,
+		Body:      body,
+	}
+	return candidate, warnings
+}
+
+func decodeEnexData(resource enexResource) ([]byte, error) {
+	if encoding := strings.TrimSpace(resource.Data.Encoding); encoding != "" && encoding != "base64" {
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	compact := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, resource.Data.Value)
+	return base64.StdEncoding.DecodeString(compact)
+}
+
+func mimeExtension(mimeType string) string {
+	extensions, err := mime.ExtensionsByType(mimeType)
+	if err != nil || len(extensions) == 0 {
+		return ""
+	}
+	sort.Strings(extensions)
+	for _, ext := range extensions {
+		if ext == ".png" || ext == ".jpg" || ext == ".pdf" || ext == ".gif" {
+			return ext
+		}
+	}
+	return extensions[0]
+}
+
+type enmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*enmlNode
+}
+
+func parseENML(content string) (*enmlNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	root := &enmlNode{name: "#root"}
+	stack := []*enmlNode{root}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return root, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &enmlNode{name: strings.ToLower(t.Name.Local), attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				node.attrs[strings.ToLower(attr.Name.Local)] = attr.Value
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &enmlNode{name: "#text", text: string(t)})
+		}
+	}
+	return root, nil
+}
+
+type enmlConverter struct {
+	media func(hash string) (*enexMedia, bool)
+	warn  func(message string)
+}
+
+type enmlBlock struct {
+	b    strings.Builder
+	line strings.Builder
+}
+
+func (c enmlConverter) convert(content string) string {
+	root, err := parseENML(content)
+	if err != nil {
+		c.warn(fmt.Sprintf("note content is not valid ENML, converted what could be read: %v", err))
+	}
+	var out enmlBlock
+	c.blocks(root, &out, "")
+	out.flush()
+	text := blankLinesPattern.ReplaceAllString(out.b.String(), "\n\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " 	")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (o *enmlBlock) flush() {
+	line := o.line.String()
+	o.line.Reset()
+	if strings.TrimSpace(line) == "" {
+		if line != "" {
+			o.b.WriteString("\n")
+		}
+		return
+	}
+	o.b.WriteString(strings.TrimLeft(line, " "))
+	o.b.WriteString("\n")
+}
+
+func (o *enmlBlock) blank() {
+	o.flush()
+	o.b.WriteString("\n")
+}
+
+func (c enmlConverter) blocks(node *enmlNode, out *enmlBlock, indent string) {
+	for _, child := range node.children {
+		c.block(child, out, indent)
+	}
+}
+
+func (c enmlConverter) block(node *enmlNode, out *enmlBlock, indent string) {
+	switch node.name {
+	case "#text":
+		text := collapseSpace(node.text)
+		if out.line.Len() == 0 {
+			text = strings.TrimLeft(text, " ")
+			if text != "" {
+				out.line.WriteString(indent)
+			}
+		}
+		out.line.WriteString(text)
+	case "en-note", "#root", "body", "html", "span", "font", "section", "article", "center":
+		if isInlineContainer(node.name) && !hasBlockChild(node) {
+			c.inlineInto(node, out, indent)
+			return
+		}
+		c.blocks(node, out, indent)
+	case "div", "p":
+		if isCodeBlock(node) {
+			out.flush()
+			c.codeBlock(node, out)
+			return
+		}
+		out.flush()
+		if node.name == "p" {
+			out.blank()
+		}
+		c.blocks(node, out, indent)
+		out.flush()
+		if len(node.children) == 1 && node.children[0].name == "br" {
+			out.b.WriteString("\n")
+		}
+		if node.name == "p" {
+			out.blank()
+		}
+	case "br":
+		if out.line.Len() == 0 {
+			return
+		}
+		out.flush()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		out.blank()
+		level := int(node.name[1] - '0')
+		out.line.WriteString(strings.Repeat("#", level) + " " + strings.TrimSpace(c.inline(node)))
+		out.flush()
+		out.blank()
+	case "ul", "ol":
+		out.flush()
+		number := 0
+		for _, item := range node.children {
+			if item.name != "li" {
+				continue
+			}
+			number++
+			marker := "- "
+			if node.name == "ol" {
+				marker = fmt.Sprintf("%d. ", number)
+			}
+			c.listItem(item, out, indent, marker)
+		}
+		out.flush()
+	case "li":
+		c.listItem(node, out, indent, "- ")
+	case "en-todo":
+		prefix := "[ ] "
+		if strings.EqualFold(node.attrs["checked"], "true") {
+			prefix = "[x] "
+		}
+		if out.line.Len() == 0 {
+			out.line.WriteString(indent + "- " + prefix)
+			return
+		}
+		out.line.WriteString(prefix)
+	case "hr":
+		out.blank()
+		out.line.WriteString("---")
+		out.flush()
+		out.blank()
+	case "blockquote":
+		out.flush()
+		var inner enmlBlock
+		c.blocks(node, &inner, "")
+		inner.flush()
+		for _, line := range strings.Split(strings.TrimSpace(inner.b.String()), "\n") {
+			out.b.WriteString(indent + strings.TrimRight("> "+line, " ") + "\n")
+		}
+		out.blank()
+	case "pre":
+		out.flush()
+		c.codeBlock(node, out)
+	case "table":
+		out.blank()
+		c.table(node, out)
+		out.blank()
+	case "en-crypt":
+		c.warn("encrypted content was skipped")
+	case "style", "script", "title", "head":
+	default:
+		c.inlineNode(node, out, indent)
+	}
+}
+
+func (c enmlConverter) inlineInto(node *enmlNode, out *enmlBlock, indent string) {
+	for _, child := range node.children {
+		c.block(child, out, indent)
+	}
+}
+
+func (c enmlConverter) inlineNode(node *enmlNode, out *enmlBlock, indent string) {
+	text := c.inlineElement(node)
+	if text == "" {
+		return
+	}
+	if out.line.Len() == 0 {
+		text = strings.TrimLeft(text, " ")
+		out.line.WriteString(indent)
+	}
+	out.line.WriteString(text)
+}
+
+func (c enmlConverter) listItem(item *enmlNode, out *enmlBlock, indent, marker string) {
+	out.flush()
+	var inner enmlBlock
+	nested := make([]*enmlNode, 0)
+	for _, child := range item.children {
+		if child.name == "ul" || child.name == "ol" {
+			nested = append(nested, child)
+			continue
+		}
+		c.block(child, &inner, "")
+	}
+	inner.flush()
+	text := strings.TrimSpace(inner.b.String())
+	text = strings.TrimPrefix(text, "- ")
+	text = strings.ReplaceAll(text, "\n", " ")
+	out.b.WriteString(indent + marker + text + "\n")
+	for _, list := range nested {
+		c.block(list, out, indent+"  ")
+	}
+}
+
+func (c enmlConverter) codeBlock(node *enmlNode, out *enmlBlock) {
+	var b strings.Builder
+	var collect func(n *enmlNode)
+	collect = func(n *enmlNode) {
+		for _, child := range n.children {
+			switch child.name {
+			case "#text":
+				b.WriteString(child.text)
+			case "br":
+				b.WriteString("\n")
+			case "div", "p":
+				if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+					b.WriteString("\n")
+				}
+				collect(child)
+			default:
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+	out.blank()
+	out.b.WriteString("```\n" + strings.Trim(b.String(), "\n") + "\n```\n")
+	out.blank()
+}
+
+func (c enmlConverter) table(node *enmlNode, out *enmlBlock) {
+	rows := make([][]string, 0)
+	var walk func(n *enmlNode)
+	walk = func(n *enmlNode) {
+		for _, child := range n.children {
+			if child.name == "tr" {
+				row := make([]string, 0)
+				for _, cell := range child.children {
+					if cell.name == "td" || cell.name == "th" {
+						text := strings.TrimSpace(strings.ReplaceAll(c.inline(cell), "\n", " "))
+						row = append(row, strings.ReplaceAll(text, "|", "\\|"))
+					}
+				}
+				rows = append(rows, row)
+				continue
+			}
+			walk(child)
+		}
+	}
+	walk(node)
+	if len(rows) == 0 {
+		return
+	}
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		out.b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			out.b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+}
+
+func (c enmlConverter) inline(node *enmlNode) string {
+	var b strings.Builder
+	for _, child := range node.children {
+		switch child.name {
+		case "#text":
+			b.WriteString(collapseSpace(child.text))
+		case "br", "div", "p":
+			if child.name != "br" {
+				b.WriteString(c.inline(child))
+			}
+			b.WriteString(" ")
+		default:
+			b.WriteString(c.inlineElement(child))
+		}
+	}
+	return b.String()
+}
+
+func (c enmlConverter) inlineElement(node *enmlNode) string {
+	switch node.name {
+	case "#text":
+		return collapseSpace(node.text)
+	case "b", "strong":
+		return wrapInline("**", c.inline(node))
+	case "i", "em":
+		return wrapInline("*", c.inline(node))
+	case "s", "strike", "del":
+		return wrapInline("~~", c.inline(node))
+	case "code", "tt":
+		return "`" + strings.ReplaceAll(plainText(node), "`", "'") + "`"
+	case "a":
+		text := strings.TrimSpace(c.inline(node))
+		href := strings.TrimSpace(node.attrs["href"])
+		if href == "" {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		return "[" + text + "](" + strings.ReplaceAll(href, " ", "%20") + ")"
+	case "img":
+		src := strings.TrimSpace(node.attrs["src"])
+		if src == "" || strings.HasPrefix(src, "data:") {
+			c.warn("inline image without a usable source was skipped")
+			return ""
+		}
+		return "![" + node.attrs["alt"] + "](" + src + ")"
+	case "en-media":
+		item, ok := c.media(node.attrs["hash"])
+		if !ok {
+			c.warn(fmt.Sprintf("missing resource %s", node.attrs["hash"]))
+			return ""
+		}
+		if strings.HasPrefix(item.mime, "image/") {
+			return "![" + item.name + "](" + item.asset + ")"
+		}
+		return "[" + item.name + "](" + item.asset + ")"
+	case "en-todo":
+		if strings.EqualFold(node.attrs["checked"], "true") {
+			return "[x] "
+		}
+		return "[ ] "
+	case "en-crypt":
+		c.warn("encrypted content was skipped")
+		return ""
+	case "style", "script":
+		return ""
+	default:
+		return c.inline(node)
+	}
+}
+
+func wrapInline(marker, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	trail := text[len(strings.TrimRight(text, " ")):]
+	return lead + marker + trimmed + marker + trail
+}
+
+func plainText(node *enmlNode) string {
+	if node.name == "#text" {
+		return node.text
+	}
+	var b strings.Builder
+	for _, child := range node.children {
+		b.WriteString(plainText(child))
+	}
+	return b.String()
+}
+
+func collapseSpace(text string) string {
+	var b bytes.Buffer
+	space := false
+	for _, r := range text {
+		if r == ' ' || r == '\n' || r == '\t' || r == '\r' || r == '\u00a0' {
+			if !space {
+				b.WriteRune(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isInlineContainer(name string) bool {
+	return name == "span" || name == "font"
+}
+
+func hasBlockChild(node *enmlNode) bool {
+	for _, child := range node.children {
+		switch child.name {
+		case "div", "p", "ul", "ol", "table", "pre", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6", "hr":
+			return true
+		}
+		if isInlineContainer(child.name) && hasBlockChild(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCodeBlock(node *enmlNode) bool {
+	return strings.Contains(strings.ReplaceAll(node.attrs["style"], " ", ""), "-en-codeblock:true")
+}
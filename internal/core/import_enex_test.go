@@ -0,0 +1,52 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestENMLToMarkdown(t *testing.T) {
+	media := map[string]*enexMedia{
+		"abc": {name: "shot.png", mime: "image/png", asset: "assets/shot.png"},
+		"def": {name: "spec.pdf", mime: "application/pdf", asset: "assets/spec.pdf"},
+	}
+	var warnings []string
+	converter := enmlConverter{
+		media: func(hash string) (*enexMedia, bool) {
+			item, ok := media[hash]
+			return item, ok
+		},
+		warn: func(message string) { warnings = append(warnings, message) },
+	}
+
+	cases := []struct {
+		name string
+		enml string
+		want string
+	}{
+		{"lines", `<en-note><div>first</div><div><br/></div><div>second</div></en-note>`, "first\n\nsecond"},
+		{"todos", `<en-note><div><en-todo checked="true"/>done</div><div><en-todo/>open</div></en-note>`, "- [x] done\n- [ ] open"},
+		{"inline", `<en-note><div><b>bold</b>, <i>em</i>, <a href="https://x.io">link</a></div></en-note>`, "**bold**, *em*, [link](https://x.io)"},
+		{"entities", `<en-note><div>a&nbsp;&amp;&nbsp;b</div></en-note>`, "a & b"},
+		{"lists", `<en-note><ol><li>one</li><li>two<ul><li>sub</li></ul></li></ol></en-note>`, "1. one\n2. two\n  - sub"},
+		{"indented lists", "<en-note>\n  <ol>\n    <li>alpha</li>\n    <li>beta</li>\n  </ol>\n</en-note>", "1. alpha\n2. beta"},
+		{"media", `<en-note><div><en-media hash="abc" type="image/png"/></div><div><en-media hash="def"/></div></en-note>`, "![shot.png](assets/shot.png)\n[spec.pdf](assets/spec.pdf)"},
+		{"heading", `<en-note><h1>Title</h1><div>body</div></en-note>`, "# Title\n\nbody"},
+		{"code", `<en-note><div style="-en-codeblock: true"><div>a := 1</div><div>b := 2</div></div></en-note>`, "```\na := 1\nb := 2\n```"},
+		{"table", `<en-note><table><tr><td>k</td><td>v</td></tr><tr><td>1</td><td>2</td></tr></table></en-note>`, "| k | v |\n| --- | --- |\n| 1 | 2 |"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := converter.convert(`<?xml version="1.0"?><!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">` + tc.enml)
+			if got != tc.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+
+	converter.convert(`<en-note><div><en-media hash="missing"/></div><en-crypt>xyz</en-crypt></en-note>`)
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "missing resource") || !strings.Contains(warnings[1], "encrypted") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
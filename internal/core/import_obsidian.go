@@ -0,0 +1,620 @@
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+var (
+	markdownLinkPattern = regexp.MustCompile(`(!?)\[([^\]\n]*)\]\(([^)\s]+)\)`)
+	blockIDPattern      = regexp.MustCompile(`\s\^[A-Za-z0-9-]+\s*$`)
+)
+
+type ObsidianOptions struct {
+	DryRun       bool
+	Domain       string
+	DailyFolders []string
+	DailyFormat  string
+	DomainFrom   string
+	NestedTags   string
+	TitleFrom    string
+	SkipFolders  []string
+}
+
+func DefaultObsidianOptions(cfg vault.Config) ObsidianOptions {
+	return ObsidianOptions{
+		DailyFolders: cfg.Strings("import.obsidian.daily_folders", []string{"Daily", "Daily Notes", "Journal"}),
+		DailyFormat:  cfg.String("import.obsidian.daily_format", "YYYY-MM-DD"),
+		DomainFrom:   cfg.String("import.obsidian.domain_from", "folder"),
+		NestedTags:   cfg.String("import.obsidian.nested_tags", "flatten"),
+		TitleFrom:    cfg.String("import.obsidian.title_from", "filename"),
+		SkipFolders:  cfg.Strings("import.obsidian.skip_folders", []string{"Templates"}),
+	}
+}
+
+type obsidianFile struct {
+	rel        string
+	properties map[string]any
+	body       string
+	candidate  importCandidate
+}
+
+type obsidianImporter struct {
+	root        string
+	opts        ObsidianOptions
+	notes       map[string]int
+	attachments map[string]string
+	files       []*obsidianFile
+	assetNames  map[string]string
+	namer       *assetNamer
+	warnings    []string
+}
+
+func (s *Service) ImportObsidian(dir string, opts ObsidianOptions) (ImportReport, error) {
+	report := ImportReport{Source: dir, DryRun: opts.DryRun}
+	if err := validateObsidianOptions(opts); err != nil {
+		return report, err
+	}
+	if err := s.checkImportSource(dir); err != nil {
+		return report, err
+	}
+
+	im := &obsidianImporter{
+		root:        dir,
+		opts:        opts,
+		notes:       map[string]int{},
+		attachments: map[string]string{},
+		assetNames:  map[string]string{},
+	}
+	if err := im.scan(); err != nil {
+		return report, err
+	}
+	if len(im.files) == 0 {
+		return report, fmt.Errorf("no markdown files found in %s", dir)
+	}
+
+	namer, err := s.newAssetNamer()
+	if err != nil {
+		return report, err
+	}
+	im.namer = namer
+
+	for index, file := range im.files {
+		im.prepare(index, file)
+	}
+	candidates := make([]importCandidate, 0, len(im.files))
+	for _, file := range im.files {
+		im.translate(file)
+		candidates = append(candidates, file.candidate)
+	}
+
+	existingDaily, err := vault.ListNotes(s.root, NoteFilter{Kind: "daily"})
+	if err != nil {
+		return report, err
+	}
+	dailyDates := map[string]bool{}
+	for _, item := range existingDaily {
+		dailyDates[item.Note.CreatedAt.Format("2006-01-02")] = true
+	}
+	filtered := make([]importCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.note.Kind == "daily" {
+			date := candidate.note.CreatedAt.Format("2006-01-02")
+			if dailyDates[date] {
+				report.Skipped = append(report.Skipped, ImportItem{Source: candidate.source, Title: candidate.note.Title, Reason: fmt.Sprintf("daily note for %s already exists", date)})
+				continue
+			}
+			dailyDates[date] = true
+		}
+		filtered = append(filtered, candidate)
+	}
+
+	report.Warnings = im.warnings
+	if err := s.writeImported(filtered, &report); err != nil {
+		return report, err
+	}
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+func validateObsidianOptions(opts ObsidianOptions) error {
+	if opts.Domain != "" && !vault.IsValidDomainID(opts.Domain) {
+		return fmt.Errorf("invalid domain %q: use lowercase kebab-case", opts.Domain)
+	}
+	switch opts.DomainFrom {
+	case "folder", "property", "none":
+	default:
+		return fmt.Errorf("invalid domain_from %q: use folder, property, or none", opts.DomainFrom)
+	}
+	switch opts.NestedTags {
+	case "flatten", "leaf", "parent", "all":
+	default:
+		return fmt.Errorf("invalid nested_tags %q: use flatten, leaf, parent, or all", opts.NestedTags)
+	}
+	switch opts.TitleFrom {
+	case "filename", "heading":
+	default:
+		return fmt.Errorf("invalid title_from %q: use filename or heading", opts.TitleFrom)
+	}
+	return nil
+}
+
+func (im *obsidianImporter) scan() error {
+	info, err := os.Stat(im.root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", im.root)
+	}
+
+	skip := map[string]bool{}
+	for _, folder := range im.opts.SkipFolders {
+		skip[strings.ToLower(strings.Trim(filepath.ToSlash(folder), "/"))] = true
+	}
+
+	return filepath.WalkDir(im.root, func(current string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if current == im.root {
+			return nil
+		}
+		rel, err := filepath.Rel(im.root, current)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(d.Name(), ".") || skip[strings.ToLower(rel)] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		ext := strings.ToLower(path.Ext(rel))
+		switch ext {
+		case ".md":
+			content, err := os.ReadFile(current)
+			if err != nil {
+				return err
+			}
+			properties, body, err := splitOptionalFrontmatter(content)
+			if err != nil {
+				im.warn(rel, err.Error())
+				properties, body = nil, string(content)
+			}
+			file := &obsidianFile{rel: rel, properties: properties, body: strings.TrimSpace(body)}
+			file.candidate.source = rel
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			file.candidate.note.UpdatedAt = info.ModTime().UTC().Truncate(time.Second)
+			im.files = append(im.files, file)
+		case ".canvas":
+			im.warn(rel, "canvas files are not supported")
+		default:
+			lower := strings.ToLower(rel)
+			im.attachments[lower] = current
+			if _, exists := im.attachments[strings.ToLower(path.Base(rel))]; !exists {
+				im.attachments[strings.ToLower(path.Base(rel))] = current
+			}
+		}
+		return nil
+	})
+}
+
+func (im *obsidianImporter) warn(rel, message string) {
+	im.warnings = append(im.warnings, fmt.Sprintf("%s: %s", rel, message))
+}
+
+func (im *obsidianImporter) prepare(index int, file *obsidianFile) {
+	stem := strings.TrimSuffix(path.Base(file.rel), path.Ext(file.rel))
+	noteRel := strings.ToLower(strings.TrimSuffix(file.rel, path.Ext(file.rel)))
+	for _, key := range []string{noteRel, strings.ToLower(stem)} {
+		if _, exists := im.notes[key]; !exists {
+			im.notes[key] = index
+		}
+	}
+	aliases := propertyStrings(file.properties["aliases"])
+	if alias, ok := file.properties["aliases"].(string); ok {
+		aliases = []string{alias}
+	}
+	for _, alias := range aliases {
+		if _, exists := im.notes[strings.ToLower(alias)]; !exists {
+			im.notes[strings.ToLower(alias)] = index
+		}
+	}
+
+	note := &file.candidate.note
+	created := note.UpdatedAt
+	if value, ok := propertyTime(file.properties, "created", "date", "created_at"); ok {
+		created = value
+	}
+	if value, ok := propertyTime(file.properties, "updated", "modified", "updated_at"); ok {
+		note.UpdatedAt = value
+	}
+
+	dir := path.Dir(file.rel)
+	if date, ok := im.dailyDate(dir, stem); ok {
+		created = time.Date(date.Year(), date.Month(), date.Day(), 9, 0, 0, 0, time.UTC)
+		note.Title = fmt.Sprintf("Daily %s", date.Format("2006-01-02"))
+		note.Kind = "daily"
+		note.Tags = []string{"daily"}
+	} else {
+		note.Kind = "note"
+		note.Title = stem
+		if im.opts.TitleFrom == "heading" {
+			if heading := FirstHeading(file.body); heading != "" {
+				note.Title = heading
+			}
+		}
+		if title, ok := file.properties["title"].(string); ok && strings.TrimSpace(title) != "" {
+			note.Title = strings.TrimSpace(title)
+		}
+		note.Domain = im.domainFor(file, dir)
+	}
+	if note.UpdatedAt.Before(created) {
+		note.UpdatedAt = created
+	}
+	note.CreatedAt = created
+	note.ID = vault.NewULID(created)
+	im.notes[strings.ToLower(note.ID)] = index
+	note.Links = []string{}
+
+	if status, ok := file.properties["status"].(string); ok && strings.EqualFold(status, vault.StatusArchived) {
+		note.Status = vault.StatusArchived
+	}
+}
+
+func (im *obsidianImporter) dailyDate(dir, stem string) (time.Time, bool) {
+	inDailyFolder := false
+	for _, folder := range im.opts.DailyFolders {
+		folder = strings.ToLower(strings.Trim(filepath.ToSlash(folder), "/"))
+		lowerDir := strings.ToLower(dir)
+		if lowerDir == folder || strings.HasPrefix(lowerDir, folder+"/") {
+			inDailyFolder = true
+			break
+		}
+	}
+	if !inDailyFolder {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(momentToGoLayout(im.opts.DailyFormat), stem)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func momentToGoLayout(format string) string {
+	return strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02", "M", "1", "D", "2").Replace(format)
+}
+
+func (im *obsidianImporter) domainFor(file *obsidianFile, dir string) string {
+	switch im.opts.DomainFrom {
+	case "property":
+		if domain, ok := file.properties["domain"].(string); ok && vault.IsValidDomainID(domain) {
+			return strings.ToLower(domain)
+		}
+	case "folder":
+		if dir != "." {
+			return vault.Slugify(strings.Split(dir, "/")[0])
+		}
+	}
+	return im.opts.Domain
+}
+
+func (im *obsidianImporter) mapTag(raw string) []string {
+	raw = strings.Trim(strings.TrimPrefix(strings.TrimSpace(raw), "#"), "/")
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, "/")
+	var values []string
+	switch {
+	case len(parts) == 1 || im.opts.NestedTags == "flatten":
+		values = []string{raw}
+	case im.opts.NestedTags == "leaf":
+		values = []string{parts[len(parts)-1]}
+	case im.opts.NestedTags == "parent":
+		values = []string{parts[0]}
+	default:
+		for i := range parts {
+			values = append(values, strings.Join(parts[:i+1], "/"))
+		}
+	}
+
+	tags := make([]string, 0, len(values))
+	for _, value := range values {
+		if tag := vault.NormalizeTag(value); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (im *obsidianImporter) translate(file *obsidianFile) {
+	note := &file.candidate.note
+
+	rawTags := append(propertyStrings(file.properties["tags"]), propertyStrings(file.properties["tag"])...)
+	rawTags = append(rawTags, rawHashtags(file.body)...)
+	for _, raw := range rawTags {
+		note.Tags = append(note.Tags, im.mapTag(raw)...)
+	}
+	note.Tags = vault.SanitizeTags(note.Tags)
+
+	for key := range file.properties {
+		switch key {
+		case "tags", "tag", "aliases", "title", "created", "date", "created_at", "updated", "modified", "updated_at":
+		case "domain":
+			if im.opts.DomainFrom != "property" {
+				im.warn(file.rel, `property "domain" ignored (domain_from is not "property")`)
+			}
+		case "status":
+			if status, _ := file.properties[key].(string); !strings.EqualFold(status, vault.StatusArchived) {
+				im.warn(file.rel, fmt.Sprintf("property %q was not imported", key))
+			}
+		default:
+			im.warn(file.rel, fmt.Sprintf("property %q was not imported", key))
+		}
+	}
+
+	lines := strings.Split(file.body, "\n")
+	inFence := false
+	blockRefs := false
+	for i, line := range lines {
+		if isFenceLine(line) {
+			if !inFence {
+				lang := strings.ToLower(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "`~")))
+				if strings.HasPrefix(lang, "dataview") || lang == "query" {
+					im.warn(file.rel, fmt.Sprintf("line %d: %s block kept as code", i+1, lang))
+				}
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if strings.Contains(line, "%%") {
+			im.warn(file.rel, fmt.Sprintf("line %d: %%%% comment kept as text", i+1))
+		}
+		if blockIDPattern.MatchString(line) {
+			blockRefs = true
+		}
+		line = markdownLinkPattern.ReplaceAllStringFunc(line, func(raw string) string {
+			match := markdownLinkPattern.FindStringSubmatch(raw)
+			return im.translateMarkdownLink(file, raw, match[1] == "!", match[2], match[3])
+		})
+		line = wikiLinkPattern.ReplaceAllStringFunc(line, func(raw string) string {
+			match := wikiLinkPattern.FindStringSubmatch(raw)
+			return im.translateWikiLink(file, raw, parseWikiLink(match[2], match[1] == "!"))
+		})
+		lines[i] = line
+	}
+	if blockRefs {
+		im.warn(file.rel, "block IDs (^id) kept as text")
+	}
+	note.Body = strings.TrimSpace(strings.Join(lines, "\n"))
+	if note.Body == "" {
+		note.Body = note.Title
+	}
+}
+
+func (im *obsidianImporter) translateWikiLink(file *obsidianFile, raw string, link WikiLink) string {
+	if link.Target == "" {
+		return raw
+	}
+	if strings.HasPrefix(link.Heading, "^") {
+		im.warn(file.rel, fmt.Sprintf("block reference %s linked to the whole note", raw))
+		link.Heading = ""
+	}
+
+	if target, ok := im.resolveNote(link.Target); ok {
+		linked := im.files[target].candidate.note.ID
+		im.addLink(file, linked)
+		if link.Embed {
+			im.warn(file.rel, fmt.Sprintf("note embed %s converted to a link", raw))
+		}
+		ref := linked
+		if link.Heading != "" {
+			ref += "#" + link.Heading
+		}
+		return "[[" + ref + "|" + link.Label + "]]"
+	}
+
+	if asset, ok := im.resolveAttachment(file, link.Target); ok {
+		label := link.Label
+		if label == link.Target || isNumericSize(label) {
+			label = path.Base(link.Target)
+		}
+		if link.Embed && IsImagePath(link.Target) {
+			return "![" + label + "](" + asset + ")"
+		}
+		return "[" + label + "](" + asset + ")"
+	}
+
+	im.warn(file.rel, fmt.Sprintf("unresolved link %s kept as text", raw))
+	return raw
+}
+
+func (im *obsidianImporter) translateMarkdownLink(file *obsidianFile, raw string, image bool, label, target string) string {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "mailto:") {
+		return raw
+	}
+	decoded, err := url.PathUnescape(target)
+	if err != nil {
+		decoded = target
+	}
+	heading := ""
+	if idx := strings.Index(decoded, "#"); idx >= 0 {
+		decoded, heading = decoded[:idx], decoded[idx+1:]
+	}
+	relative := path.Clean(path.Join(path.Dir(file.rel), decoded))
+
+	if strings.EqualFold(path.Ext(decoded), ".md") {
+		for _, candidate := range []string{relative, decoded} {
+			if index, ok := im.resolveNote(strings.TrimSuffix(candidate, path.Ext(candidate))); ok {
+				linked := im.files[index].candidate.note.ID
+				im.addLink(file, linked)
+				ref := linked
+				if heading != "" {
+					ref += "#" + heading
+				}
+				if label == "" {
+					label = im.files[index].candidate.note.Title
+				}
+				return "[[" + ref + "|" + label + "]]"
+			}
+		}
+		im.warn(file.rel, fmt.Sprintf("unresolved link %s kept as text", raw))
+		return raw
+	}
+
+	for _, candidate := range []string{relative, decoded} {
+		if asset, ok := im.resolveAttachment(file, candidate); ok {
+			prefix := ""
+			if image {
+				prefix = "!"
+			}
+			return prefix + "[" + label + "](" + asset + ")"
+		}
+	}
+	im.warn(file.rel, fmt.Sprintf("missing attachment %s kept as text", raw))
+	return raw
+}
+
+func (im *obsidianImporter) resolveNote(target string) (int, bool) {
+	key := strings.ToLower(strings.TrimSuffix(strings.Trim(target, "/"), ".md"))
+	if index, ok := im.notes[key]; ok {
+		return index, true
+	}
+	if index, ok := im.notes[strings.ToLower(path.Base(key))]; ok && !strings.Contains(key, "/") {
+		return index, true
+	}
+	return 0, false
+}
+
+func (im *obsidianImporter) addLink(file *obsidianFile, id string) {
+	note := &file.candidate.note
+	if id == note.ID {
+		return
+	}
+	for _, existing := range note.Links {
+		if existing == id {
+			return
+		}
+	}
+	note.Links = append(note.Links, id)
+}
+
+func (im *obsidianImporter) resolveAttachment(file *obsidianFile, target string) (string, bool) {
+	key := strings.ToLower(strings.Trim(target, "/"))
+	source, ok := im.attachments[key]
+	if !ok {
+		source, ok = im.attachments[strings.ToLower(path.Base(key))]
+	}
+	if !ok {
+		return "", false
+	}
+
+	if name, done := im.assetNames[source]; done {
+		im.addAsset(file, source, name)
+		return "assets/" + name, true
+	}
+
+	hash, err := fileSHA256(source)
+	if err != nil {
+		im.warn(file.rel, fmt.Sprintf("cannot read attachment %s: %v", target, err))
+		return "", false
+	}
+	name := im.namer.name(path.Base(filepath.ToSlash(source)), hash)
+	im.assetNames[source] = name
+	im.addAsset(file, source, name)
+	return "assets/" + name, true
+}
+
+func (im *obsidianImporter) addAsset(file *obsidianFile, source, name string) {
+	rel, err := filepath.Rel(im.root, source)
+	if err != nil {
+		rel = source
+	}
+	for _, existing := range file.candidate.assets {
+		if existing.path == source {
+			return
+		}
+	}
+	file.candidate.assets = append(file.candidate.assets, ImportAsset{
+		Source: filepath.ToSlash(rel),
+		Target: "assets/" + name,
+		path:   source,
+	})
+}
+
+func isNumericSize(label string) bool {
+	if label == "" {
+		return false
+	}
+	for _, r := range label {
+		if (r < '0' || r > '9') && r != 'x' {
+			return false
+		}
+	}
+	return true
+}
+
+func propertyStrings(value any) []string {
+	switch typed := value.(type) {
+	case string:
+		return strings.FieldsFunc(typed, func(r rune) bool { return r == ',' || r == ' ' })
+	case []any:
+		out := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if text, ok := item.(string); ok {
+				out = append(out, text)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func propertyTime(properties map[string]any, keys ...string) (time.Time, bool) {
+	for _, key := range keys {
+		switch value := properties[key].(type) {
+		case time.Time:
+			return value.UTC(), true
+		case string:
+			for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+				if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+					return t.UTC(), true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func fileSHA256(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("%x", sum), nil
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	body := "Notes on #Go and #k8s/networking, see page#anchor.\n`#inline` is code\n```\n#fenced\n```\n# Heading\n(#paren) #123"
+	got := ExtractHashtags(body)
+	want := []string{"go", "k8s-networking", "paren"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestInferTitleAndFirstHeading(t *testing.T) {
+	cases := []struct {
+		body    string
+		infer   string
+		heading string
+	}{
+		{"", "Untitled", ""},
+		{"Plain first line\nmore", "Plain first line", ""},
+		{"# Worker leak\nbody", "# Worker leak", "Worker leak"},
+		{strings.Repeat("é", 80), strings.Repeat("é", 72), ""},
+		{strings.Repeat("a", 71) + "日本", strings.Repeat("a", 71) + "日", ""},
+		{"intro\n\n## Details ##\n", "intro", "Details"},
+		{"```\n# not a heading\n```\n### Real", "```", "Real"},
+	}
+	for _, tc := range cases {
+		if got := InferTitle(tc.body); got != tc.infer {
+			t.Fatalf("InferTitle(%q) = %q, want %q", tc.body, got, tc.infer)
+		}
+		if got := FirstHeading(tc.body); got != tc.heading {
+			t.Fatalf("FirstHeading(%q) = %q, want %q", tc.body, got, tc.heading)
+		}
+	}
+}
+
+func TestImportMarkdownNonASCIIFolders(t *testing.T) {
+	src := t.TempDir()
+	for _, rel := range []string{"Проекты/plan.md", "日記/day.md", "Go Notes/tips.md"} {
+		path := filepath.Join(src, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("# "+rel+"\n\nbody"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	report, err := New(t.TempDir()).ImportMarkdown(src, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	domains := map[string]string{}
+	for _, item := range report.Imported {
+		domains[strings.Split(item.Source, "/")[0]] = item.Domain
+	}
+	if domains["Go Notes"] != "go-notes" || !strings.HasPrefix(domains["Проекты"], "untitled-") || !strings.HasPrefix(domains["日記"], "untitled-") || domains["Проекты"] == domains["日記"] {
+		t.Fatalf("unexpected domains: %v", domains)
+	}
+	if len(report.Warnings) != 2 || !strings.Contains(report.Warnings[0], "--domain") {
+		t.Fatalf("expected a warning per non-ASCII folder: %v", report.Warnings)
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+const (
+	LogCreated  = "created"
+	LogUpdated  = "updated"
+	LogMoved    = "moved"
+	LogArchived = "archived"
+	LogDeleted  = "deleted"
+)
+
+type JournalEntry struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	NoteID     string    `json:"note_id"`
+	Title      string    `json:"title"`
+	Domain     string    `json:"domain"`
+	FromDomain string    `json:"from_domain,omitempty"`
+	RelPath    string    `json:"rel_path"`
+}
+
+type LogEvent struct {
+	JournalEntry
+	Source string
+}
+
+func (s *Service) JournalPath() string {
+	return filepath.Join(s.root, ".nitid", "journal.ndjson")
+}
+
+func (s *Service) appendJournal(entry JournalEntry) error {
+	entry.Time = entry.Time.UTC().Truncate(time.Second)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("journal %s: %w", entry.Action, err)
+	}
+	file, err := os.OpenFile(s.JournalPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("journal %s: %w", entry.Action, err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("journal %s: %w", entry.Action, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("journal %s: %w", entry.Action, err)
+	}
+	return nil
+}
+
+func (s *Service) Journal() ([]JournalEntry, error) {
+	file, err := os.Open(s.JournalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make([]JournalEntry, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Action == "" || entry.NoteID == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (s *Service) Log(since time.Time, domain string) ([]LogEvent, error) {
+	entries, err := s.Journal()
+	if err != nil {
+		return nil, err
+	}
+	notes, err := vault.ListNotes(s.root, NoteFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	journaled := map[string]bool{}
+	events := make([]LogEvent, 0)
+	for _, entry := range entries {
+		journaled[entry.NoteID+"@"+entry.Time.UTC().Format(time.RFC3339)] = true
+		if entry.Time.Before(since) {
+			continue
+		}
+		if domain != "" && entry.Domain != domain && entry.FromDomain != domain {
+			continue
+		}
+		events = append(events, LogEvent{JournalEntry: entry, Source: "journal"})
+	}
+
+	for _, item := range notes {
+		note := item.Note
+		if domain != "" && note.Domain != domain {
+			continue
+		}
+		base := JournalEntry{NoteID: note.ID, Title: note.Title, Domain: note.Domain, RelPath: item.RelPath}
+		if !note.CreatedAt.Before(since) {
+			created := base
+			created.Time, created.Action = note.CreatedAt, LogCreated
+			events = append(events, LogEvent{JournalEntry: created, Source: "note"})
+		}
+		updatedAt := note.UpdatedAt.UTC().Truncate(time.Second)
+		if updatedAt.Before(since) || !updatedAt.After(note.CreatedAt.UTC().Truncate(time.Second)) || journaled[note.ID+"@"+updatedAt.Format(time.RFC3339)] {
+			continue
+		}
+		updated := base
+		updated.Time, updated.Action = note.UpdatedAt, LogUpdated
+		events = append(events, LogEvent{JournalEntry: updated, Source: "note"})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Time.Equal(events[j].Time) {
+			return events[i].Time.After(events[j].Time)
+		}
+		return strings.Compare(events[i].NoteID, events[j].NoteID) < 0
+	})
+	return events, nil
+}
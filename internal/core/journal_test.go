@@ -0,0 +1,95 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+func TestLogMergesNotesAndJournal(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc, notes := newTestService(t,
+		Note{Title: "Old note", CreatedAt: base.AddDate(0, -1, 0), UpdatedAt: base.Add(2 * time.Hour), Domain: "ops", Status: vault.StatusActive},
+		Note{Title: "Fresh note", CreatedAt: base.Add(time.Hour), Domain: "engineering", Status: vault.StatusActive},
+		Note{Title: "Doomed", CreatedAt: base.Add(3 * time.Hour)},
+	)
+	ids := []string{notes[0].ID, notes[1].ID, notes[2].ID}
+
+	if _, err := svc.Move(ids[1], "ops"); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if _, err := svc.Delete(ids[2]); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := os.WriteFile(svc.JournalPath(), append(mustRead(t, svc.JournalPath()), []byte("not json\n")...), 0o644); err != nil {
+		t.Fatalf("corrupt journal: %v", err)
+	}
+
+	events, err := svc.Log(base, "")
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	got := make([]string, 0, len(events))
+	for _, event := range events {
+		got = append(got, event.Action+":"+event.Title+":"+event.Source)
+	}
+	if len(got) != 4 {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	journaled := strings.Join(got[:2], "|")
+	if journaled != "moved:Fresh note:journal|deleted:Doomed:journal" && journaled != "deleted:Doomed:journal|moved:Fresh note:journal" {
+		t.Fatalf("expected journal events first, got %v", got)
+	}
+	if rest := strings.Join(got[2:], "|"); rest != "updated:Old note:note|created:Fresh note:note" {
+		t.Fatalf("unexpected derived events: %v", got)
+	}
+	for _, event := range events {
+		if event.Action == LogMoved && (event.FromDomain != "engineering" || event.Domain != "ops") {
+			t.Fatalf("unexpected move event: %+v", event)
+		}
+	}
+
+	events, err = svc.Log(base, "engineering")
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	if len(events) != 1 || events[0].Action != LogMoved {
+		t.Fatalf("expected only the move out of engineering, got %+v", events)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return content
+}
+
+func TestJournalFailureIsAWarning(t *testing.T) {
+	svc, notes := newTestService(t,
+		Note{Title: "Keep", CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+		Note{Title: "Drop", CreatedAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+	)
+	if err := os.Mkdir(svc.JournalPath(), 0o755); err != nil {
+		t.Fatalf("block journal: %v", err)
+	}
+
+	result, err := svc.Move(notes[0].ID, "ops")
+	if err != nil {
+		t.Fatalf("move should succeed without a journal: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "journal moved") {
+		t.Fatalf("expected journal warning, got %v", result.Warnings)
+	}
+	if result, err = svc.Archive(notes[0].ID); err != nil || len(result.Warnings) != 1 {
+		t.Fatalf("archive: %v %v", result.Warnings, err)
+	}
+	if result, err = svc.Delete(notes[1].ID); err != nil || len(result.Warnings) != 1 {
+		t.Fatalf("delete: %v %v", result.Warnings, err)
+	}
+}
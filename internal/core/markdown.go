@@ -0,0 +1,551 @@
+package core
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type MarkdownOptions struct {
+	ResolveWikiLink func(target string, embed bool) (string, bool)
+	RewriteURL      func(url string) string
+}
+
+type WikiLink struct {
+	Target  string
+	Label   string
+	Heading string
+	Embed   bool
+}
+
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listItemPattern  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	rulePattern      = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	tableSepPattern  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	wikiLinkPattern  = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+?)\]\]`)
+	imageExtPattern  = regexp.MustCompile(`(?i)\.(png|jpe?g|gif|svg|webp|bmp)$`)
+	orderedMarkerPat = regexp.MustCompile(`^\d+[.)]$`)
+)
+
+func RenderMarkdownHTML(source string, opts MarkdownOptions) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	r := markdownRenderer{opts: opts, anchors: map[string]bool{}}
+	return r.blocks(lines)
+}
+
+func ParseWikiLinks(body string) []WikiLink {
+	links := make([]WikiLink, 0)
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, match := range wikiLinkPattern.FindAllStringSubmatch(line, -1) {
+			links = append(links, parseWikiLink(match[2], match[1] == "!"))
+		}
+	}
+	return links
+}
+
+func parseWikiLink(raw string, embed bool) WikiLink {
+	link := WikiLink{Embed: embed}
+	target := raw
+	if idx := strings.Index(target, "|"); idx >= 0 {
+		link.Label = strings.TrimSpace(target[idx+1:])
+		target = target[:idx]
+	}
+	if idx := strings.Index(target, "#"); idx >= 0 {
+		link.Heading = strings.TrimSpace(target[idx+1:])
+		target = target[:idx]
+	}
+	link.Target = strings.TrimSpace(target)
+	if link.Label == "" {
+		link.Label = link.Target
+		if link.Label == "" {
+			link.Label = link.Heading
+		}
+	}
+	return link
+}
+
+func HeadingAnchor(text string) string {
+	var b strings.Builder
+	prevDash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			prevDash = false
+			continue
+		}
+		if !prevDash && b.Len() > 0 {
+			b.WriteRune('-')
+			prevDash = true
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+func IsImagePath(value string) bool {
+	return imageExtPattern.MatchString(value)
+}
+
+type markdownRenderer struct {
+	opts    MarkdownOptions
+	anchors map[string]bool
+}
+
+func (r markdownRenderer) uniqueAnchor(text string) string {
+	base := HeadingAnchor(text)
+	anchor := base
+	for n := 1; r.anchors[anchor]; n++ {
+		anchor = base + "-" + strconv.Itoa(n)
+	}
+	r.anchors[anchor] = true
+	return anchor
+}
+
+func (r markdownRenderer) blocks(lines []string) string {
+	var b strings.Builder
+	paragraph := make([]string, 0)
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		b.WriteString(r.inline(strings.Join(paragraph, "\n")))
+		b.WriteString("</p>\n")
+		paragraph = paragraph[:0]
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case isFenceLine(line):
+			flush()
+			fence := strings.TrimSpace(line)
+			marker := fence[:3]
+			lang := strings.TrimSpace(strings.TrimLeft(fence, marker[:1]))
+			code := make([]string, 0)
+			i++
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), marker) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			if lang != "" {
+				b.WriteString(`<pre><code class="language-` + html.EscapeString(strings.Fields(lang)[0]) + `">`)
+			} else {
+				b.WriteString("<pre><code>")
+			}
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+		case headingPattern.MatchString(trimmed):
+			flush()
+			match := headingPattern.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(match[1]))
+			anchor := r.uniqueAnchor(match[2])
+			b.WriteString("<h" + level + ` id="` + html.EscapeString(anchor) + `">`)
+			b.WriteString(r.inline(match[2]))
+			b.WriteString("</h" + level + ">\n")
+		case rulePattern.MatchString(line) && len(paragraph) == 0:
+			b.WriteString("<hr>\n")
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			quoted := make([]string, 0)
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(t, ">") {
+					i--
+					break
+				}
+				t = strings.TrimPrefix(t, ">")
+				quoted = append(quoted, strings.TrimPrefix(t, " "))
+			}
+			b.WriteString("<blockquote>\n")
+			b.WriteString(r.blocks(quoted))
+			b.WriteString("</blockquote>\n")
+		case listItemPattern.MatchString(line) && (len(paragraph) == 0 || leadingSpaces(line) == 0):
+			flush()
+			end := listBlockEnd(lines, i)
+			b.WriteString(r.list(lines[i:end]))
+			i = end - 1
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableSepPattern.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			flush()
+			end := i + 2
+			for end < len(lines) && strings.Contains(lines[end], "|") && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			b.WriteString(r.table(lines[i], lines[i+2:end]))
+			i = end - 1
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return b.String()
+}
+
+func isFenceLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+func leadingSpaces(line string) int {
+	count := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			count++
+		case '\t':
+			count += 4
+		default:
+			return count
+		}
+	}
+	return count
+}
+
+func listBlockEnd(lines []string, start int) int {
+	base := leadingSpaces(lines[start])
+	i := start + 1
+	for i < len(lines) {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next < len(lines) && (leadingSpaces(lines[next]) > base || (listItemPattern.MatchString(lines[next]) && leadingSpaces(lines[next]) == base)) {
+				i = next
+				continue
+			}
+			return i
+		}
+		if leadingSpaces(line) > base {
+			i++
+			continue
+		}
+		if listItemPattern.MatchString(line) && leadingSpaces(line) == base {
+			i++
+			continue
+		}
+		return i
+	}
+	return i
+}
+
+func (r markdownRenderer) list(lines []string) string {
+	first := listItemPattern.FindStringSubmatch(lines[0])
+	base := leadingSpaces(lines[0])
+	tag := "ul"
+	if orderedMarkerPat.MatchString(first[2]) {
+		tag = "ol"
+	}
+
+	type listItem struct {
+		text     string
+		children []string
+	}
+	items := make([]listItem, 0)
+	for _, line := range lines {
+		match := listItemPattern.FindStringSubmatch(line)
+		if match != nil && leadingSpaces(line) == base {
+			items = append(items, listItem{text: match[3]})
+			continue
+		}
+		if len(items) == 0 {
+			continue
+		}
+		current := &items[len(items)-1]
+		current.children = append(current.children, dedent(line, base+2))
+	}
+
+	var b strings.Builder
+	b.WriteString("<" + tag + ">\n")
+	for _, item := range items {
+		text := item.text
+		checkbox := ""
+		switch {
+		case strings.HasPrefix(text, "[ ] "):
+			checkbox = `<input type="checkbox" disabled> `
+			text = text[4:]
+		case strings.HasPrefix(text, "[x] "), strings.HasPrefix(text, "[X] "):
+			checkbox = `<input type="checkbox" checked disabled> `
+			text = text[4:]
+		}
+
+		inlineLines := []string{strings.TrimSpace(text)}
+		rest := item.children
+		for len(rest) > 0 {
+			t := strings.TrimSpace(rest[0])
+			if t == "" || listItemPattern.MatchString(rest[0]) || isFenceLine(rest[0]) {
+				break
+			}
+			inlineLines = append(inlineLines, t)
+			rest = rest[1:]
+		}
+
+		if checkbox != "" {
+			b.WriteString(`<li class="task">`)
+		} else {
+			b.WriteString("<li>")
+		}
+		b.WriteString(checkbox)
+		b.WriteString(r.inline(strings.Join(inlineLines, "\n")))
+		if nested := strings.TrimSpace(r.blocks(rest)); nested != "" {
+			b.WriteString("\n")
+			b.WriteString(nested)
+			b.WriteString("\n")
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return b.String()
+}
+
+func dedent(line string, width int) string {
+	removed := 0
+	for i, r := range line {
+		if removed >= width || (r != ' ' && r != '\t') {
+			return line[i:]
+		}
+		if r == '\t' {
+			removed += 4
+		} else {
+			removed++
+		}
+	}
+	return ""
+}
+
+func (r markdownRenderer) table(header string, rows []string) string {
+	var b strings.Builder
+	b.WriteString("<table>\n<thead><tr>")
+	for _, cell := range splitTableRow(header) {
+		b.WriteString("<th>" + r.inline(cell) + "</th>")
+	}
+	b.WriteString("</tr></thead>\n<tbody>\n")
+	for _, row := range rows {
+		b.WriteString("<tr>")
+		for _, cell := range splitTableRow(row) {
+			b.WriteString("<td>" + r.inline(cell) + "</td>")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	return b.String()
+}
+
+func splitTableRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+	cells := strings.Split(row, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+func (r markdownRenderer) inline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_[]()#!<>|~", rune(rest[1])):
+			b.WriteString(html.EscapeString(rest[1:2]))
+			i += 2
+			continue
+		case rest[0] == '\n':
+			b.WriteString("\n")
+			i++
+			continue
+		case rest[0] == '`':
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			fence := strings.Repeat("`", ticks)
+			if end := strings.Index(rest[ticks:], fence); end >= 0 {
+				code := strings.TrimSpace(rest[ticks : ticks+end])
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += ticks + end + ticks
+				continue
+			}
+		case strings.HasPrefix(rest, "![[") || strings.HasPrefix(rest, "[["):
+			embed := rest[0] == '!'
+			open := 2
+			if embed {
+				open = 3
+			}
+			if end := strings.Index(rest[open:], "]]"); end >= 0 && !strings.Contains(rest[open:open+end], "\n") {
+				b.WriteString(r.wikiLink(parseWikiLink(rest[open:open+end], embed)))
+				i += open + end + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "!["):
+			if label, url, n, ok := parseInlineLink(rest[1:]); ok {
+				if !safeURL(url) {
+					b.WriteString(html.EscapeString(rest[:1+n]))
+					i += 1 + n
+					continue
+				}
+				b.WriteString(`<img src="` + html.EscapeString(r.rewrite(url)) + `" alt="` + html.EscapeString(label) + `">`)
+				i += 1 + n
+				continue
+			}
+		case rest[0] == '[':
+			if label, url, n, ok := parseInlineLink(rest); ok {
+				if !safeURL(url) {
+					b.WriteString(html.EscapeString(rest[:n]))
+					i += n
+					continue
+				}
+				b.WriteString(`<a href="` + html.EscapeString(r.rewrite(url)) + `">` + r.inline(label) + "</a>")
+				i += n
+				continue
+			}
+		case rest[0] == '<':
+			if end := strings.IndexByte(rest, '>'); end > 0 {
+				url := rest[1:end]
+				if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "mailto:") {
+					b.WriteString(`<a href="` + html.EscapeString(url) + `">` + html.EscapeString(url) + "</a>")
+					i += end + 1
+					continue
+				}
+			}
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if end := strings.Index(rest[2:], rest[:2]); end > 0 {
+				b.WriteString("<strong>" + r.inline(rest[2:2+end]) + "</strong>")
+				i += end + 4
+				continue
+			}
+		case strings.HasPrefix(rest, "~~"):
+			if end := strings.Index(rest[2:], "~~"); end > 0 {
+				b.WriteString("<del>" + r.inline(rest[2:2+end]) + "</del>")
+				i += end + 4
+				continue
+			}
+		case rest[0] == '*' || (rest[0] == '_' && (i == 0 || !isWordByte(text[i-1]))):
+			marker := rest[:1]
+			if end := strings.Index(rest[1:], marker); end > 0 && rest[1] != ' ' && (marker == "*" || 2+end >= len(rest) || !isWordByte(rest[2+end])) {
+				b.WriteString("<em>" + r.inline(rest[1:1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func parseInlineLink(text string) (string, string, int, bool) {
+	depth := 0
+	closeLabel := -1
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeLabel = i
+			}
+		case '\n':
+			return "", "", 0, false
+		}
+		if closeLabel >= 0 {
+			break
+		}
+	}
+	if closeLabel < 0 || closeLabel+1 >= len(text) || text[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+	end, depth := -1, 0
+	for i := closeLabel + 2; i < len(text) && end < 0; i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				end = i - closeLabel - 2
+			}
+			depth--
+		case '\n':
+			return "", "", 0, false
+		}
+	}
+	if end < 0 {
+		return "", "", 0, false
+	}
+	url := strings.TrimSpace(text[closeLabel+2 : closeLabel+2+end])
+	if idx := strings.IndexAny(url, " \t"); idx >= 0 {
+		url = url[:idx]
+	}
+	url = strings.Trim(url, "<>")
+	return text[1:closeLabel], url, closeLabel + 3 + end, true
+}
+
+func safeURL(url string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+	colon := strings.IndexByte(cleaned, ':')
+	if colon < 0 || strings.ContainsAny(cleaned[:colon], "/?#") {
+		return true
+	}
+	switch strings.ToLower(cleaned[:colon]) {
+	case "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+func (r markdownRenderer) rewrite(url string) string {
+	if r.opts.RewriteURL == nil {
+		return url
+	}
+	return r.opts.RewriteURL(url)
+}
+
+func (r markdownRenderer) wikiLink(link WikiLink) string {
+	href, ok := "", false
+	if r.opts.ResolveWikiLink != nil {
+		href, ok = r.opts.ResolveWikiLink(link.Target, link.Embed)
+	}
+	label := html.EscapeString(link.Label)
+	if !ok {
+		return `<span class="wikilink missing">` + label + "</span>"
+	}
+	if link.Heading != "" {
+		href += "#" + HeadingAnchor(link.Heading)
+	}
+	if link.Embed && IsImagePath(link.Target) {
+		return `<img src="` + html.EscapeString(href) + `" alt="` + label + `">`
+	}
+	class := "wikilink"
+	if link.Embed {
+		class = "wikilink embed"
+	}
+	return `<a class="` + class + `" href="` + html.EscapeString(href) + `">` + label + "</a>"
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownHTML(t *testing.T) {
+	opts := MarkdownOptions{
+		ResolveWikiLink: func(target string, embed bool) (string, bool) {
+			if target == "Known" {
+				return "known.html", true
+			}
+			return "", false
+		},
+		RewriteURL: func(url string) string {
+			return strings.Replace(url, "assets/", "../assets/", 1)
+		},
+	}
+
+	cases := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"heading", "## Retry *loop*", []string{`<h2 id="retry-loop">Retry <em>loop</em></h2>`}},
+		{"escape", "a <b> & c", []string{"<p>a &lt;b&gt; &amp; c</p>"}},
+		{"inline", "**bold** `x<y` ~~old~~", []string{"<strong>bold</strong>", "<code>x&lt;y</code>", "<del>old</del>"}},
+		{"link", "[docs](https://example.com/a)", []string{`<a href="https://example.com/a">docs</a>`}},
+		{"image", "![diagram](assets/d.png)", []string{`<img src="../assets/d.png" alt="diagram">`}},
+		{"wiki", "[[Known|see]] [[Known#Next Steps]] [[Nope]]", []string{
+			`<a class="wikilink" href="known.html">see</a>`,
+			`<a class="wikilink" href="known.html#next-steps">Known</a>`,
+			`<span class="wikilink missing">Nope</span>`,
+		}},
+		{"tasks", "- [ ] open\n- [x] done\n  - child", []string{
+			`<li class="task"><input type="checkbox" disabled> open</li>`,
+			`<input type="checkbox" checked disabled> done`,
+			"<ul>\n<li>child</li>\n</ul>",
+		}},
+		{"ordered", "1. one\n2. two", []string{"<ol>\n<li>one</li>\n<li>two</li>\n</ol>"}},
+		{"fence", "```go\nif a < b {\n```", []string{`<pre><code class="language-go">if a &lt; b {</code></pre>`}},
+		{"quote", "> quoted\n> text", []string{"<blockquote>\n<p>quoted\ntext</p>\n</blockquote>"}},
+		{"table", "| a | b |\n|---|:-:|\n| 1 | 2 |", []string{"<th>a</th><th>b</th>", "<td>1</td><td>2</td>"}},
+		{"rule", "---", []string{"<hr>"}},
+		{"snake_case", "use snake_case_names", []string{"<p>use snake_case_names</p>"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := RenderMarkdownHTML(tc.source, opts)
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("expected %q in output:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownHTMLUnsafeLinks(t *testing.T) {
+	cases := []struct {
+		source string
+		want   string
+	}{
+		{"[x](javascript:alert(1))", "<p>[x](javascript:alert(1))</p>"},
+		{"[x](JavaScript:alert(document.cookie))", "<p>[x](JavaScript:alert(document.cookie))</p>"},
+		{"[x]( javascript:alert(1))", "<p>[x]( javascript:alert(1))</p>"},
+		{"![x](data:image/svg+xml;base64,PHN2Zz4=)", "<p>![x](data:image/svg+xml;base64,PHN2Zz4=)</p>"},
+		{"[x](data:text/html,<script>alert(1)</script>)", "<p>[x](data:text/html,&lt;script&gt;alert(1)&lt;/script&gt;)</p>"},
+		{"[wiki](https://en.wikipedia.org/wiki/Go_(language))", `<a href="https://en.wikipedia.org/wiki/Go_(language)">wiki</a>`},
+		{"[mail](mailto:a@example.com) [top](#intro) [rel](../b.md?x=a:b)", `<a href="mailto:a@example.com">mail</a> <a href="#intro">top</a> <a href="../b.md?x=a:b">rel</a>`},
+	}
+	for _, tc := range cases {
+		got := RenderMarkdownHTML(tc.source, MarkdownOptions{})
+		if !strings.Contains(got, tc.want) {
+			t.Fatalf("render %q: expected %q in output:\n%s", tc.source, tc.want, got)
+		}
+		if strings.Contains(strings.ToLower(got), `href="javascript`) || strings.Contains(got, `src="data:`) || strings.Contains(got, `href="data:`) {
+			t.Fatalf("render %q: unsafe URL kept:\n%s", tc.source, got)
+		}
+	}
+}
+
+func TestRenderMarkdownHTMLUniqueAnchors(t *testing.T) {
+	got := RenderMarkdownHTML("## Notes\n\n> ## Notes\n\n### Notes\n\n## Notes 1", MarkdownOptions{})
+	for _, want := range []string{`<h2 id="notes">`, `<h2 id="notes-1">`, `<h3 id="notes-2">`, `<h2 id="notes-1-1">`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %s in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseWikiLinks(t *testing.T) {
+	links := ParseWikiLinks("See [[Alpha|the alpha]] and ![[diagram.png]]\n```\n[[ignored]]\n```\n[[Beta#Plan]]")
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %+v", links)
+	}
+	if links[0].Target != "Alpha" || links[0].Label != "the alpha" || links[0].Embed {
+		t.Fatalf("unexpected first link: %+v", links[0])
+	}
+	if links[1].Target != "diagram.png" || !links[1].Embed {
+		t.Fatalf("unexpected embed: %+v", links[1])
+	}
+	if links[2].Target != "Beta" || links[2].Heading != "Plan" {
+		t.Fatalf("unexpected heading link: %+v", links[2])
+	}
+}
@@ -0,0 +1,311 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictNewerWins = "newer-wins"
+
+	noteRecordSchemaVersion = 1
+)
+
+type NoteRecord struct {
+	SchemaVersion int            `json:"schema_version"`
+	Type          string         `json:"type"`
+	Frontmatter   map[string]any `json:"frontmatter"`
+	Body          string         `json:"body"`
+	RelPath       string         `json:"rel_path,omitempty"`
+}
+
+type NDJSONOptions struct {
+	DryRun     bool
+	OnConflict string
+}
+
+func IsConflictPolicy(value string) bool {
+	switch value {
+	case ConflictSkip, ConflictOverwrite, ConflictNewerWins:
+		return true
+	default:
+		return false
+	}
+}
+
+func NewNoteRecord(item NoteFile) NoteRecord {
+	note := item.Note
+	frontmatter := map[string]any{}
+	for key, value := range note.Extra {
+		frontmatter[key] = value
+	}
+	frontmatter["id"] = note.ID
+	frontmatter["title"] = note.Title
+	frontmatter["created_at"] = note.CreatedAt.UTC().Format(time.RFC3339)
+	frontmatter["updated_at"] = note.UpdatedAt.UTC().Format(time.RFC3339)
+	frontmatter["domain"] = note.Domain
+	frontmatter["tags"] = nonNilList(note.Tags)
+	frontmatter["status"] = note.Status
+	frontmatter["kind"] = note.Kind
+	frontmatter["links"] = nonNilList(note.Links)
+
+	return NoteRecord{
+		SchemaVersion: noteRecordSchemaVersion,
+		Type:          "note",
+		Frontmatter:   frontmatter,
+		Body:          note.Body,
+		RelPath:       item.RelPath,
+	}
+}
+
+func (r NoteRecord) Note(now time.Time) (Note, error) {
+	if r.SchemaVersion > noteRecordSchemaVersion {
+		return Note{}, fmt.Errorf("unsupported schema_version %d", r.SchemaVersion)
+	}
+	if r.Type != "" && r.Type != "note" {
+		return Note{}, fmt.Errorf("unsupported record type %q", r.Type)
+	}
+	if r.Frontmatter == nil {
+		return Note{}, errors.New("missing frontmatter")
+	}
+
+	fm := r.Frontmatter
+	note := Note{Body: strings.TrimSpace(r.Body)}
+	var err error
+	if note.ID, err = recordString(fm, "id"); err != nil {
+		return Note{}, err
+	}
+	if note.Title, err = recordString(fm, "title"); err != nil {
+		return Note{}, err
+	}
+	if note.Domain, err = recordString(fm, "domain"); err != nil {
+		return Note{}, err
+	}
+	if note.Status, err = recordString(fm, "status"); err != nil {
+		return Note{}, err
+	}
+	if note.Kind, err = recordString(fm, "kind"); err != nil {
+		return Note{}, err
+	}
+	if note.Tags, err = recordStrings(fm, "tags"); err != nil {
+		return Note{}, err
+	}
+	if note.Links, err = recordStrings(fm, "links"); err != nil {
+		return Note{}, err
+	}
+	if note.CreatedAt, err = recordTime(fm, "created_at", now); err != nil {
+		return Note{}, err
+	}
+	if note.UpdatedAt, err = recordTime(fm, "updated_at", note.CreatedAt); err != nil {
+		return Note{}, err
+	}
+
+	if note.ID == "" {
+		note.ID = vault.NewULID(note.CreatedAt)
+	}
+	if note.Kind == "" {
+		note.Kind = "note"
+	}
+	note.Tags = vault.SanitizeTags(note.Tags)
+	for key, value := range fm {
+		switch key {
+		case "id", "title", "created_at", "updated_at", "domain", "tags", "status", "kind", "links":
+			continue
+		}
+		if note.Extra == nil {
+			note.Extra = map[string]any{}
+		}
+		note.Extra[key] = normalizeJSONValue(value)
+	}
+	return note, nil
+}
+
+func (s *Service) ImportNDJSON(source string, r io.Reader, opts NDJSONOptions) (ImportReport, error) {
+	report := ImportReport{Source: source, DryRun: opts.DryRun}
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictSkip
+	}
+	if !IsConflictPolicy(opts.OnConflict) {
+		return report, fmt.Errorf("invalid conflict policy %q: use skip, overwrite, or newer-wins", opts.OnConflict)
+	}
+
+	existing, err := vault.ListNotes(s.root, NoteFilter{})
+	if err != nil {
+		return report, err
+	}
+	byID := make(map[string]NoteFile, len(existing))
+	for _, item := range existing {
+		byID[item.Note.ID] = item
+	}
+
+	if !opts.DryRun {
+		if err := s.Init(); err != nil {
+			return report, err
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	seen := map[string]int{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		label := fmt.Sprintf("line %d", lineNo)
+
+		var record NoteRecord
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&record); err != nil {
+			report.Skipped = append(report.Skipped, ImportItem{Source: label, Reason: "invalid JSON: " + err.Error()})
+			continue
+		}
+		note, err := record.Note(now)
+		if err != nil {
+			report.Skipped = append(report.Skipped, ImportItem{Source: label, Reason: err.Error()})
+			continue
+		}
+		item := ImportItem{Source: label, NoteID: note.ID, Title: note.Title, Domain: note.Domain, Tags: note.Tags}
+		if err := vault.ValidateNoteForWrite(note); err != nil {
+			item.Reason = err.Error()
+			report.Skipped = append(report.Skipped, item)
+			continue
+		}
+		if first, dup := seen[note.ID]; dup {
+			item.Reason = fmt.Sprintf("duplicate of line %d", first)
+			report.Skipped = append(report.Skipped, item)
+			continue
+		}
+		seen[note.ID] = lineNo
+
+		currentPath := ""
+		if current, ok := byID[note.ID]; ok {
+			switch opts.OnConflict {
+			case ConflictSkip:
+				item.Reason = "already exists"
+				report.Skipped = append(report.Skipped, item)
+				continue
+			case ConflictNewerWins:
+				if !note.UpdatedAt.After(current.Note.UpdatedAt) {
+					item.Reason = "existing note is not older"
+					report.Skipped = append(report.Skipped, item)
+					continue
+				}
+			}
+			currentPath = current.Path
+			item.Replaced = true
+		}
+
+		if opts.DryRun {
+			path, err := vault.ResolveNotePath(s.root, note)
+			if err != nil {
+				return report, err
+			}
+			item.RelPath = toRelOrAbs(s.root, path)
+		} else {
+			relPath, err := vault.SaveNote(s.root, currentPath, note)
+			if err != nil {
+				return report, err
+			}
+			item.RelPath = relPath
+		}
+		report.Imported = append(report.Imported, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func recordString(fm map[string]any, key string) (string, error) {
+	value, ok := fm[key]
+	if !ok || value == nil {
+		return "", nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return strings.TrimSpace(text), nil
+}
+
+func recordStrings(fm map[string]any, key string) ([]string, error) {
+	value, ok := fm[key]
+	if !ok || value == nil {
+		return []string{}, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of strings", key)
+	}
+	out := make([]string, 0, len(list))
+	for _, entry := range list {
+		text, ok := entry.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of strings", key)
+		}
+		out = append(out, text)
+	}
+	return out, nil
+}
+
+func recordTime(fm map[string]any, key string, fallback time.Time) (time.Time, error) {
+	text, err := recordString(fm, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if text == "" {
+		return fallback, nil
+	}
+	t, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be RFC3339", key)
+	}
+	return t.UTC(), nil
+}
+
+func normalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		for i := range v {
+			v[i] = normalizeJSONValue(v[i])
+		}
+		return v
+	case map[string]any:
+		for key := range v {
+			v[key] = normalizeJSONValue(v[key])
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func nonNilList(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
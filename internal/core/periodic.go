@@ -0,0 +1,227 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+var doneTaskPattern = regexp.MustCompile(`^\s*[-*+] \[[xX]\]\s+(\S.*)$`)
+
+type Period struct {
+	Kind  string
+	Start time.Time
+	End   time.Time
+	Label string
+}
+
+type RollupItem struct {
+	Note NoteFile
+	Text string
+}
+
+type Rollup struct {
+	Dailies   []NoteFile
+	Created   []NoteFile
+	Archived  []NoteFile
+	Completed []RollupItem
+	Wins      []RollupItem
+}
+
+func PeriodFor(kind string, date time.Time) (Period, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 9, 0, 0, 0, time.UTC)
+	switch kind {
+	case "weekly":
+		offset := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -offset)
+		year, week := start.ISOWeek()
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 0, 7), Label: fmt.Sprintf("%04d-W%02d", year, week)}, nil
+	case "monthly":
+		start := time.Date(day.Year(), day.Month(), 1, 9, 0, 0, 0, time.UTC)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 1, 0), Label: start.Format("2006-01")}, nil
+	case "quarterly":
+		quarter := (int(day.Month()) - 1) / 3
+		start := time.Date(day.Year(), time.Month(quarter*3+1), 1, 9, 0, 0, 0, time.UTC)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 3, 0), Label: fmt.Sprintf("%04d-Q%d", start.Year(), quarter+1)}, nil
+	default:
+		return Period{}, fmt.Errorf("unknown period %q: use weekly, monthly, or quarterly", kind)
+	}
+}
+
+func (p Period) Title() string {
+	return strings.ToUpper(p.Kind[:1]) + p.Kind[1:] + " " + p.Label
+}
+
+func (p Period) LastDay() time.Time {
+	return p.End.AddDate(0, 0, -1)
+}
+
+func (p Period) Contains(t time.Time) bool {
+	day := t.UTC().Format("2006-01-02")
+	return day >= p.Start.Format("2006-01-02") && day < p.End.Format("2006-01-02")
+}
+
+func (s *Service) FindPeriodic(p Period) (NoteFile, bool, error) {
+	notes, err := vault.ListNotes(s.root, NoteFilter{Kind: p.Kind})
+	if err != nil {
+		return NoteFile{}, false, err
+	}
+	for _, item := range notes {
+		if p.Contains(item.Note.CreatedAt) {
+			return item, true, nil
+		}
+	}
+	return NoteFile{}, false, nil
+}
+
+func (s *Service) Rollup(p Period) (Rollup, error) {
+	notes, err := vault.ListNotes(s.root, NoteFilter{})
+	if err != nil {
+		return Rollup{}, err
+	}
+	SortNotes(notes, "created", true)
+
+	var rollup Rollup
+	for _, item := range notes {
+		note := item.Note
+		if note.Kind == "daily" {
+			if !p.Contains(note.CreatedAt) {
+				continue
+			}
+			rollup.Dailies = append(rollup.Dailies, item)
+			for _, task := range tasksMatching(note.Body, doneTaskPattern) {
+				rollup.Completed = append(rollup.Completed, RollupItem{Note: item, Text: task})
+			}
+			for _, win := range sectionItems(note.Body, "wins") {
+				rollup.Wins = append(rollup.Wins, RollupItem{Note: item, Text: win})
+			}
+			continue
+		}
+		if vault.IsPeriodicKind(note.Kind) {
+			continue
+		}
+		if p.Contains(note.CreatedAt) {
+			rollup.Created = append(rollup.Created, item)
+		}
+		if note.Status == vault.StatusArchived && p.Contains(note.UpdatedAt) {
+			rollup.Archived = append(rollup.Archived, item)
+		}
+	}
+	sort.SliceStable(rollup.Archived, func(i, j int) bool {
+		return rollup.Archived[i].Note.UpdatedAt.Before(rollup.Archived[j].Note.UpdatedAt)
+	})
+	return rollup, nil
+}
+
+func (r Rollup) Markdown() string {
+	var b strings.Builder
+	writeSection := func(heading string, lines []string) {
+		b.WriteString("### " + heading + "\n\n")
+		if len(lines) == 0 {
+			b.WriteString("_None._\n\n")
+			return
+		}
+		b.WriteString(strings.Join(lines, "\n"))
+		b.WriteString("\n\n")
+	}
+
+	lines := make([]string, 0, len(r.Dailies))
+	for _, item := range r.Dailies {
+		lines = append(lines, "- "+noteWikiLink(item.Note))
+	}
+	writeSection(fmt.Sprintf("Daily notes (%d)", len(r.Dailies)), lines)
+
+	lines = lines[:0]
+	for _, item := range r.Created {
+		lines = append(lines, "- "+noteWikiLink(item.Note)+noteContext(item.Note))
+	}
+	writeSection(fmt.Sprintf("Created (%d)", len(r.Created)), lines)
+
+	lines = lines[:0]
+	for _, item := range r.Archived {
+		lines = append(lines, "- "+noteWikiLink(item.Note)+noteContext(item.Note))
+	}
+	writeSection(fmt.Sprintf("Archived (%d)", len(r.Archived)), lines)
+
+	lines = lines[:0]
+	for _, item := range r.Completed {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", item.Text, noteWikiLink(item.Note.Note)))
+	}
+	writeSection(fmt.Sprintf("Completed tasks (%d)", len(r.Completed)), lines)
+
+	lines = lines[:0]
+	for _, item := range r.Wins {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", item.Text, noteWikiLink(item.Note.Note)))
+	}
+	writeSection(fmt.Sprintf("Wins (%d)", len(r.Wins)), lines)
+
+	return strings.TrimSpace(b.String())
+}
+
+func noteWikiLink(note Note) string {
+	return fmt.Sprintf("[[%s|%s]]", note.ID, note.Title)
+}
+
+func noteContext(note Note) string {
+	if note.Domain == "" {
+		return ""
+	}
+	return " (" + note.Domain + ")"
+}
+
+func tasksMatching(body string, pattern *regexp.Regexp) []string {
+	tasks := make([]string, 0)
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := pattern.FindStringSubmatch(line); match != nil {
+			tasks = append(tasks, strings.TrimSpace(match[1]))
+		}
+	}
+	return tasks
+}
+
+func sectionItems(body, heading string) []string {
+	items := make([]string, 0)
+	inSection := false
+	level := 0
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFenceLine(line) {
+			inFence = !inFence
+		}
+		if !inFence {
+			if match := headingPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+				if inSection && len(match[1]) <= level {
+					break
+				}
+				if !inSection && strings.EqualFold(strings.TrimSpace(match[2]), heading) {
+					inSection = true
+					level = len(match[1])
+				}
+				continue
+			}
+		}
+		if !inSection {
+			continue
+		}
+		text := strings.TrimSpace(line)
+		for _, prefix := range []string{"- [x] ", "- [X] ", "- [ ] ", "- ", "* ", "+ "} {
+			text = strings.TrimPrefix(text, prefix)
+		}
+		if text != "" && !isFenceLine(text) {
+			items = append(items, text)
+		}
+	}
+	return items
+}
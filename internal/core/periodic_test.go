@@ -0,0 +1,48 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPeriodFor(t *testing.T) {
+	cases := []struct {
+		kind  string
+		date  string
+		label string
+		start string
+		end   string
+	}{
+		{kind: "weekly", date: "2026-10-18", label: "2026-W42", start: "2026-10-12", end: "2026-10-19"},
+		{kind: "weekly", date: "2026-01-01", label: "2026-W01", start: "2025-12-29", end: "2026-01-05"},
+		{kind: "weekly", date: "2026-10-12", label: "2026-W42", start: "2026-10-12", end: "2026-10-19"},
+		{kind: "monthly", date: "2026-02-14", label: "2026-02", start: "2026-02-01", end: "2026-03-01"},
+		{kind: "quarterly", date: "2026-11-30", label: "2026-Q4", start: "2026-10-01", end: "2027-01-01"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.kind+" "+tc.date, func(t *testing.T) {
+			date, _ := time.Parse("2006-01-02", tc.date)
+			period, err := PeriodFor(tc.kind, date)
+			if err != nil {
+				t.Fatalf("period: %v", err)
+			}
+			if period.Label != tc.label || period.Start.Format("2006-01-02") != tc.start || period.End.Format("2006-01-02") != tc.end {
+				t.Fatalf("got %s %s..%s, want %s %s..%s", period.Label, period.Start.Format("2006-01-02"), period.End.Format("2006-01-02"), tc.label, tc.start, tc.end)
+			}
+		})
+	}
+
+	if _, err := PeriodFor("yearly", time.Now()); err == nil {
+		t.Fatalf("expected error for unknown period")
+	}
+}
+
+func TestSectionItems(t *testing.T) {
+	body := "## Plan\n\n- [ ] Not a win\n\n## Wins\n\n- Shipped importer\n- [x] Closed bug\nPaired with Bo\n\n### Details\n\n- nested detail\n\n## Follow-ups\n\n- later"
+	got := sectionItems(body, "wins")
+	want := []string{"Shipped importer", "Closed bug", "Paired with Bo", "nested detail"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,538 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"nitid/internal/vault"
+)
+
+type FieldMatch struct {
+	Field     string
+	Value     string
+	Positions []int
+}
+
+type LineMatch struct {
+	Line      int
+	Text      string
+	Positions []int
+}
+
+type SearchResult struct {
+	File       NoteFile
+	Score      int
+	Matches    []FieldMatch
+	Lines      []LineMatch
+	LineOffset int
+}
+
+type RegexOptions struct {
+	CaseSensitive bool
+	Fields        []string
+}
+
+type runeSpan struct {
+	start int
+	end   int
+}
+
+func exactSearchResult(item NoteFile, query string) SearchResult {
+	result := SearchResult{File: item}
+	add := func(field, value string) {
+		if positions := substringPositions(value, query); len(positions) > 0 {
+			result.Matches = append(result.Matches, FieldMatch{Field: field, Value: value, Positions: positions})
+		}
+	}
+
+	add("title", item.Note.Title)
+	add("domain", item.Note.Domain)
+	for _, tag := range item.Note.Tags {
+		add("tag", tag)
+	}
+	for idx, line := range strings.Split(item.Note.Body, "\n") {
+		if positions := substringPositions(line, query); len(positions) > 0 {
+			result.Lines = append(result.Lines, LineMatch{Line: idx + 1, Text: line, Positions: positions})
+		}
+	}
+	result.Score = len(result.Matches) + len(result.Lines)
+	return result
+}
+
+func substringPositions(text, query string) []int {
+	needle := lowerRunes(query)
+	if len(needle) == 0 {
+		return nil
+	}
+	haystack := lowerRunes(text)
+
+	positions := make([]int, 0)
+	offset := 0
+	for offset+len(needle) <= len(haystack) {
+		idx := indexRunes(haystack[offset:], needle)
+		if idx < 0 {
+			break
+		}
+		start := offset + idx
+		positions = append(positions, spanPositions(start, start+len(needle))...)
+		offset = start + len(needle)
+	}
+	return positions
+}
+
+func bodyLineMatches(body string, positions []int) []LineMatch {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	lines := make([]LineMatch, 0)
+	lineStart := 0
+	next := 0
+	for idx, line := range strings.Split(body, "\n") {
+		length := utf8.RuneCountInString(line)
+		var linePositions []int
+		for next < len(positions) && positions[next] < lineStart+length {
+			if positions[next] >= lineStart {
+				linePositions = append(linePositions, positions[next]-lineStart)
+			}
+			next++
+		}
+		if len(linePositions) > 0 {
+			lines = append(lines, LineMatch{Line: idx + 1, Text: line, Positions: linePositions})
+		}
+		lineStart += length + 1
+	}
+	return lines
+}
+
+func (s *Service) FuzzyFind(query string, filter NoteFilter, limit int) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("find query cannot be empty")
+	}
+	if limit < 1 {
+		return nil, errors.New("limit must be at least 1")
+	}
+
+	notes, err := vault.ListNotes(s.root, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0)
+	for _, item := range notes {
+		score, matches, ok := FuzzyMatchNote(item.Note, query)
+		if !ok {
+			continue
+		}
+		result := SearchResult{File: item, Score: score, Matches: matches}
+		for _, match := range matches {
+			if match.Field == "body" {
+				result.Lines = bodyLineMatches(match.Value, match.Positions)
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	addLineOffsets(results)
+	return results, nil
+}
+
+func (s *Service) RegexFind(pattern string, opts RegexOptions, filter NoteFilter, limit int) ([]SearchResult, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.New("regex pattern cannot be empty")
+	}
+	if limit < 1 {
+		return nil, errors.New("limit must be at least 1")
+	}
+
+	fields, err := normalizeRegexFields(opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	expr := pattern
+	if !opts.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+
+	notes, err := vault.ListNotes(s.root, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0)
+	for _, item := range notes {
+		result := SearchResult{File: item}
+		if fields["title"] {
+			if positions, ok := regexPositions(re, item.Note.Title); ok {
+				result.Matches = append(result.Matches, FieldMatch{Field: "title", Value: item.Note.Title, Positions: positions})
+			}
+		}
+		if fields["tags"] {
+			for _, tag := range item.Note.Tags {
+				if positions, ok := regexPositions(re, tag); ok {
+					result.Matches = append(result.Matches, FieldMatch{Field: "tag", Value: tag, Positions: positions})
+				}
+			}
+		}
+		if fields["body"] {
+			for idx, line := range strings.Split(item.Note.Body, "\n") {
+				if positions, ok := regexPositions(re, line); ok {
+					result.Lines = append(result.Lines, LineMatch{Line: idx + 1, Text: line, Positions: positions})
+				}
+			}
+		}
+		if len(result.Matches) == 0 && len(result.Lines) == 0 {
+			continue
+		}
+		result.Score = len(result.Matches) + len(result.Lines)
+		results = append(results, result)
+		if len(results) == limit {
+			break
+		}
+	}
+	addLineOffsets(results)
+	return results, nil
+}
+
+func addLineOffsets(results []SearchResult) {
+	for i := range results {
+		if len(results[i].Lines) == 0 {
+			continue
+		}
+		if offset, err := vault.BodyLineOffset(results[i].File.Path); err == nil {
+			results[i].LineOffset = offset
+		}
+	}
+}
+
+func normalizeRegexFields(values []string) (map[string]bool, error) {
+	fields := map[string]bool{}
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch value {
+		case "":
+			continue
+		case "all":
+			fields["body"], fields["title"], fields["tags"] = true, true, true
+		case "body", "title", "tags":
+			fields[value] = true
+		default:
+			return nil, fmt.Errorf("invalid search field %q: use body, title, or tags", value)
+		}
+	}
+	if len(fields) == 0 {
+		fields["body"], fields["title"], fields["tags"] = true, true, true
+	}
+	return fields, nil
+}
+
+func regexPositions(re *regexp.Regexp, text string) ([]int, bool) {
+	spans := re.FindAllStringIndex(text, -1)
+	if len(spans) == 0 {
+		return nil, false
+	}
+
+	positions := make([]int, 0)
+	for _, span := range spans {
+		if span[0] == span[1] {
+			continue
+		}
+		start := utf8.RuneCountInString(text[:span[0]])
+		end := start + utf8.RuneCountInString(text[span[0]:span[1]])
+		positions = append(positions, spanPositions(start, end)...)
+	}
+	return positions, true
+}
+
+func (s *Service) suggestSelectors(selector string) []string {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return nil
+	}
+
+	notes, err := vault.ListNotes(s.root, NoteFilter{})
+	if err != nil {
+		return nil
+	}
+
+	prefixes := vault.UniqueIDPrefixes(notes, 8)
+	needle := []rune(strings.ToUpper(selector))
+	suggestions := make([]string, 0)
+	for _, item := range notes {
+		id := []rune(item.Note.ID)
+		candidate := id
+		if len(candidate) > len(needle) {
+			candidate = candidate[:len(needle)]
+		}
+		idMatch := len(needle) >= 4 && editDistance(needle, candidate) <= allowedTypos(len(needle))
+		_, _, titleMatch := FuzzyMatch(selector, item.Note.Title)
+		if !idMatch && !titleMatch {
+			continue
+		}
+		suggestions = append(suggestions, prefixes[item.Note.ID])
+		if len(suggestions) == 3 {
+			break
+		}
+	}
+	return suggestions
+}
+
+func FuzzyMatchNote(note Note, query string) (int, []FieldMatch, bool) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return 0, nil, false
+	}
+
+	type field struct {
+		name   string
+		value  string
+		weight int
+	}
+	fields := []field{
+		{name: "title", value: note.Title, weight: 20},
+		{name: "domain", value: note.Domain, weight: 5},
+	}
+	for _, tag := range note.Tags {
+		fields = append(fields, field{name: "tag", value: tag, weight: 10})
+	}
+	fields = append(fields, field{name: "body", value: note.Body, weight: 0})
+
+	merged := make(map[int][]int)
+	total := 0
+	for _, term := range terms {
+		bestField := -1
+		bestScore := 0
+		var bestPositions []int
+		for idx, f := range fields {
+			positions, score, ok := FuzzyMatch(term, f.value)
+			if !ok {
+				continue
+			}
+			score += f.weight
+			if bestField < 0 || score > bestScore {
+				bestField = idx
+				bestScore = score
+				bestPositions = positions
+			}
+		}
+		if bestField < 0 {
+			return 0, nil, false
+		}
+		total += bestScore
+		merged[bestField] = append(merged[bestField], bestPositions...)
+	}
+
+	indexes := make([]int, 0, len(merged))
+	for idx := range merged {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	matches := make([]FieldMatch, 0, len(indexes))
+	for _, idx := range indexes {
+		matches = append(matches, FieldMatch{
+			Field:     fields[idx].name,
+			Value:     fields[idx].value,
+			Positions: uniqueSorted(merged[idx]),
+		})
+	}
+	return total, matches, true
+}
+
+func FuzzyMatch(term, text string) ([]int, int, bool) {
+	needle := lowerRunes(strings.TrimSpace(term))
+	if len(needle) == 0 {
+		return nil, 0, false
+	}
+	haystack := lowerRunes(text)
+
+	if idx := indexRunes(haystack, needle); idx >= 0 {
+		score := 100
+		if idx == 0 || !isWordRune(haystack[idx-1]) {
+			score += 10
+		}
+		return spanPositions(idx, idx+len(needle)), score, true
+	}
+
+	found := false
+	bestScore := 0
+	var bestPositions []int
+	maxTypos := allowedTypos(len(needle))
+	for _, w := range wordSpans(haystack) {
+		word := haystack[w.start:w.end]
+
+		if len(needle)*5 >= len(word)*3 {
+			if offsets, ok := subsequence(needle, word); ok {
+				score := 80 - 5*(len(word)-len(needle))
+				if !found || score > bestScore {
+					found = true
+					bestScore = score
+					bestPositions = make([]int, 0, len(offsets))
+					for _, offset := range offsets {
+						bestPositions = append(bestPositions, w.start+offset)
+					}
+				}
+			}
+		}
+
+		if maxTypos == 0 {
+			continue
+		}
+		if dist := editDistance(needle, word); dist <= maxTypos {
+			score := 70 - 15*dist
+			if !found || score > bestScore {
+				found = true
+				bestScore = score
+				bestPositions = spanPositions(w.start, w.end)
+			}
+		}
+	}
+
+	return bestPositions, bestScore, found
+}
+
+func allowedTypos(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func editDistance(a, b []rune) int {
+	rows := len(a) + 1
+	cols := len(b) + 1
+	d := make([][]int, rows)
+	for i := range d {
+		d[i] = make([]int, cols)
+		d[i][0] = i
+	}
+	for j := 0; j < cols; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minOf(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minOf(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[rows-1][cols-1]
+}
+
+func subsequence(needle, word []rune) ([]int, bool) {
+	offsets := make([]int, 0, len(needle))
+	next := 0
+	for i, r := range word {
+		if next < len(needle) && r == needle[next] {
+			offsets = append(offsets, i)
+			next++
+		}
+	}
+	return offsets, next == len(needle)
+}
+
+func wordSpans(text []rune) []runeSpan {
+	spans := make([]runeSpan, 0)
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			spans = append(spans, runeSpan{start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, runeSpan{start: start, end: len(text)})
+	}
+	return spans
+}
+
+func indexRunes(haystack, needle []rune) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+func lowerRunes(value string) []rune {
+	runes := []rune(value)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func spanPositions(start, end int) []int {
+	positions := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+func uniqueSorted(values []int) []int {
+	sort.Ints(values)
+	out := make([]int, 0, len(values))
+	for i, v := range values {
+		if i > 0 && v == values[i-1] {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func minOf(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		term      string
+		text      string
+		wantOK    bool
+		wantFirst int
+	}{
+		{name: "substring", term: "leak", text: "Worker leak", wantOK: true, wantFirst: 7},
+		{name: "missing letter", term: "goroutne", text: "stuck goroutine", wantOK: true, wantFirst: 6},
+		{name: "transposition", term: "goroutien", text: "stuck goroutine", wantOK: true, wantFirst: 6},
+		{name: "loose subsequence rejected", term: "gr", text: "goroutine", wantOK: false},
+		{name: "too many typos", term: "gorilla", text: "goroutine", wantOK: false},
+		{name: "empty term", term: " ", text: "anything", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions, _, ok := FuzzyMatch(tt.term, tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("FuzzyMatch(%q, %q) ok=%v want=%v", tt.term, tt.text, ok, tt.wantOK)
+			}
+			if ok && positions[0] != tt.wantFirst {
+				t.Fatalf("first position: got=%d want=%d (%v)", positions[0], tt.wantFirst, positions)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchNoteRequiresEveryTerm(t *testing.T) {
+	note := Note{Title: "Worker pool leak", Body: "goroutine stuck in retry", Tags: []string{"go"}}
+
+	score, matches, ok := FuzzyMatchNote(note, "workr goroutne")
+	if !ok || score <= 0 {
+		t.Fatalf("expected fuzzy match, got ok=%v score=%d", ok, score)
+	}
+	if len(matches) != 2 || matches[0].Field != "title" || matches[1].Field != "body" {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+
+	if _, _, ok := FuzzyMatchNote(note, "workr kubernetes"); ok {
+		t.Fatalf("did not expect match when one term is missing")
+	}
+}
+
+func TestRegexFindLinesAndFields(t *testing.T) {
+	root := t.TempDir()
+	svc := New(root)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	note := Note{
+		ID:        vault.NewULID(now),
+		Title:     "Panic trace",
+		CreatedAt: now,
+		UpdatedAt: now,
+		Tags:      []string{"panic-log"},
+		Kind:      "note",
+		Body:      "first line\npanic: runtime error\nPANIC again",
+	}
+	if _, err := svc.Create(note); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	results, err := svc.RegexFind(`panic:?\s`, RegexOptions{Fields: []string{"body"}}, NoteFilter{}, 10)
+	if err != nil {
+		t.Fatalf("regex find: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Lines) != 2 || len(results[0].Matches) != 0 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].Lines[0].Line != 2 || results[0].Lines[0].Positions[0] != 0 {
+		t.Fatalf("unexpected first line match: %+v", results[0].Lines[0])
+	}
+
+	results, err = svc.RegexFind(`PANIC`, RegexOptions{CaseSensitive: true}, NoteFilter{}, 10)
+	if err != nil {
+		t.Fatalf("case-sensitive regex find: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Lines) != 1 || results[0].Lines[0].Line != 3 {
+		t.Fatalf("unexpected case-sensitive results: %+v", results)
+	}
+
+	results, err = svc.RegexFind(`^panic-`, RegexOptions{Fields: []string{"tags"}}, NoteFilter{}, 10)
+	if err != nil {
+		t.Fatalf("tag regex find: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Matches) != 1 || results[0].Matches[0].Field != "tag" {
+		t.Fatalf("unexpected tag results: %+v", results)
+	}
+
+	if _, err := svc.RegexFind(`(`, RegexOptions{}, NoteFilter{}, 10); err == nil {
+		t.Fatalf("expected invalid regex error")
+	}
+	if _, err := svc.RegexFind(`x`, RegexOptions{Fields: []string{"links"}}, NoteFilter{}, 10); err == nil {
+		t.Fatalf("expected invalid field error")
+	}
+}
+
+func TestFindReturnsMatchPositions(t *testing.T) {
+	root := t.TempDir()
+	svc := New(root)
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	note := Note{
+		ID:        vault.NewULID(now),
+		Title:     "Retry policy",
+		CreatedAt: now,
+		UpdatedAt: now,
+		Kind:      "note",
+		Body:      "intro\nwe retry twice, then RETRY once more\nend",
+	}
+	if _, err := svc.Create(note); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	results, err := svc.Find("retry", NoteFilter{}, 10)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected one result, got %d", len(results))
+	}
+	if len(results[0].Matches) != 1 || results[0].Matches[0].Field != "title" {
+		t.Fatalf("unexpected field matches: %+v", results[0].Matches)
+	}
+	lines := results[0].Lines
+	if len(lines) != 1 || lines[0].Line != 2 {
+		t.Fatalf("unexpected line matches: %+v", lines)
+	}
+	if len(lines[0].Positions) != 10 || lines[0].Positions[0] != 3 || lines[0].Positions[5] != 21 {
+		t.Fatalf("unexpected positions: %v", lines[0].Positions)
+	}
+}
+
+func TestBodyLineMatches(t *testing.T) {
+	body := "alpha\nbeta gamma\ndelta"
+	lines := bodyLineMatches(body, []int{0, 11, 12, 17})
+	if len(lines) != 3 {
+		t.Fatalf("expected three lines, got %+v", lines)
+	}
+	if lines[1].Line != 2 || lines[1].Positions[0] != 5 || lines[1].Positions[1] != 6 {
+		t.Fatalf("unexpected second line: %+v", lines[1])
+	}
+	if lines[2].Line != 3 || lines[2].Positions[0] != 0 {
+		t.Fatalf("unexpected third line: %+v", lines[2])
+	}
+}
@@ -22,8 +22,9 @@ type Service struct {
 }
 
 type MutationResult struct {
-	NoteID  string
-	RelPath string
+	NoteID   string
+	RelPath  string
+	Warnings []string
 }
 
 type ValidationReport struct {
@@ -40,6 +41,34 @@ func (s *Service) Root() string {
 	return s.root
 }
 
+func (s *Service) Config() (vault.Config, error) {
+	return vault.LoadConfig(s.root)
+}
+
+func (s *Service) Location() (*time.Location, error) {
+	cfg, err := s.Config()
+	if err != nil {
+		return nil, err
+	}
+	return ResolveLocation(cfg.Timezone, os.Getenv("TZ"))
+}
+
+func ResolveLocation(configured, env string) (*time.Location, error) {
+	if name := strings.TrimSpace(configured); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q in .nitid/config.toml: use an IANA name such as Europe/Berlin", name)
+		}
+		return loc, nil
+	}
+	if name := strings.TrimPrefix(strings.TrimSpace(env), ":"); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc, nil
+		}
+	}
+	return time.UTC, nil
+}
+
 func (s *Service) Init() error {
 	return vault.CreateVaultStructure(s.root)
 }
@@ -51,6 +80,7 @@ func (s *Service) Create(note Note) (string, error) {
 	if err := s.Init(); err != nil {
 		return "", err
 	}
+	note.Body = ResolveTaskDates(note.Body, note.CreatedAt)
 	return vault.WriteNote(s.root, note)
 }
 
@@ -63,7 +93,7 @@ func (s *Service) List(filter NoteFilter, sortBy string, asc bool) ([]NoteFile,
 	return notes, nil
 }
 
-func (s *Service) Find(query string, filter NoteFilter, limit int) ([]NoteFile, error) {
+func (s *Service) Find(query string, filter NoteFilter, limit int) ([]SearchResult, error) {
 	query = strings.ToLower(strings.TrimSpace(query))
 	if query == "" {
 		return nil, errors.New("find query cannot be empty")
@@ -77,20 +107,27 @@ func (s *Service) Find(query string, filter NoteFilter, limit int) ([]NoteFile,
 		return nil, err
 	}
 
-	matches := make([]NoteFile, 0)
+	matches := make([]SearchResult, 0)
 	for _, item := range notes {
 		if NoteMatchesQuery(item.Note, query) {
-			matches = append(matches, item)
+			matches = append(matches, exactSearchResult(item, query))
 		}
 	}
 	if len(matches) > limit {
 		matches = matches[:limit]
 	}
+	addLineOffsets(matches)
 	return matches, nil
 }
 
 func (s *Service) FindBySelector(selector string) (NoteFile, error) {
-	return vault.FindNoteBySelector(s.root, selector)
+	noteFile, err := vault.FindNoteBySelector(s.root, selector)
+	if errors.Is(err, vault.ErrNoteNotFound) {
+		if suggestions := s.suggestSelectors(selector); len(suggestions) > 0 {
+			return NoteFile{}, fmt.Errorf("%w (did you mean %s?)", err, strings.Join(suggestions, ", "))
+		}
+	}
+	return noteFile, err
 }
 
 func (s *Service) FindDailyByDate(date time.Time) (NoteFile, bool, error) {
@@ -129,8 +166,11 @@ func (s *Service) Move(selector, domain string) (MutationResult, error) {
 	if err != nil {
 		return MutationResult{}, err
 	}
-
-	return MutationResult{NoteID: note.ID, RelPath: rel}, nil
+	result := MutationResult{NoteID: note.ID, RelPath: rel}
+	if err := s.appendJournal(JournalEntry{Time: note.UpdatedAt, Action: LogMoved, NoteID: note.ID, Title: note.Title, Domain: domain, FromDomain: noteFile.Note.Domain, RelPath: rel}); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	return result, nil
 }
 
 func (s *Service) Tag(selector, action, tag string) (MutationResult, error) {
@@ -175,8 +215,11 @@ func (s *Service) Archive(selector string) (MutationResult, error) {
 	if err != nil {
 		return MutationResult{}, err
 	}
-
-	return MutationResult{NoteID: note.ID, RelPath: rel}, nil
+	result := MutationResult{NoteID: note.ID, RelPath: rel}
+	if err := s.appendJournal(JournalEntry{Time: note.UpdatedAt, Action: LogArchived, NoteID: note.ID, Title: note.Title, Domain: note.Domain, RelPath: rel}); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	return result, nil
 }
 
 func (s *Service) Delete(selector string) (MutationResult, error) {
@@ -188,8 +231,11 @@ func (s *Service) Delete(selector string) (MutationResult, error) {
 	if err := os.Remove(noteFile.Path); err != nil {
 		return MutationResult{}, err
 	}
-
-	return MutationResult{NoteID: noteFile.Note.ID, RelPath: noteFile.RelPath}, nil
+	result := MutationResult{NoteID: noteFile.Note.ID, RelPath: noteFile.RelPath}
+	if err := s.appendJournal(JournalEntry{Time: time.Now(), Action: LogDeleted, NoteID: noteFile.Note.ID, Title: noteFile.Note.Title, Domain: noteFile.Note.Domain, RelPath: noteFile.RelPath}); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	return result, nil
 }
 
 func (s *Service) Edit(selector string) error {
@@ -207,7 +253,7 @@ func (s *Service) UpdateBody(selector, body string) (MutationResult, error) {
 	}
 
 	note := noteFile.Note
-	note.Body = strings.TrimRight(body, "\n")
+	note.Body = ResolveTaskDates(strings.TrimRight(body, "\n"), note.CreatedAt)
 	note.UpdatedAt = time.Now().UTC()
 
 	rel, err := vault.SaveNote(s.root, noteFile.Path, note)
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"testing"
+
+	"nitid/internal/vault"
+)
+
+func newTestService(t *testing.T, notes ...Note) (*Service, []Note) {
+	t.Helper()
+	root := t.TempDir()
+	if err := vault.CreateVaultStructure(root); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	svc := New(root)
+	created := make([]Note, 0, len(notes))
+	for _, note := range notes {
+		if note.ID == "" {
+			note.ID = vault.NewULID(note.CreatedAt)
+		}
+		if note.Kind == "" {
+			note.Kind = "note"
+		}
+		if note.Status == "" {
+			note.Status = vault.StatusInbox
+		}
+		if note.UpdatedAt.IsZero() {
+			note.UpdatedAt = note.CreatedAt
+		}
+		if _, err := svc.Create(note); err != nil {
+			t.Fatalf("create %s: %v", note.Title, err)
+		}
+		created = append(created, note)
+	}
+	return svc, created
+}
+
+func TestResolveLocation(t *testing.T) {
+	tests := []struct {
+		configured string
+		env        string
+		want       string
+		wantErr    bool
+	}{
+		{want: "UTC"},
+		{env: "Asia/Tokyo", want: "Asia/Tokyo"},
+		{env: ":Asia/Tokyo", want: "Asia/Tokyo"},
+		{env: "Not/AZone", want: "UTC"},
+		{configured: "Europe/Berlin", env: "Asia/Tokyo", want: "Europe/Berlin"},
+		{configured: "Mars/Olympus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		loc, err := ResolveLocation(tt.configured, tt.env)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for %q", tt.configured)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("resolve %q/%q: %v", tt.configured, tt.env, err)
+		}
+		if loc.String() != tt.want {
+			t.Fatalf("resolve %q/%q: got %s want %s", tt.configured, tt.env, loc, tt.want)
+		}
+	}
+}
@@ -0,0 +1,523 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const siteIndexFile = "search-index.json"
+
+var siteGeneratedEntries = []string{"index.html", siteIndexFile, "notes", "domains", "tags", "kinds", "assets"}
+
+type SiteExport struct {
+	OutDir string
+	Notes  int
+	Pages  int
+	Assets int
+}
+
+type siteSearchEntry struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Domain  string   `json:"domain"`
+	Tags    []string `json:"tags"`
+	Kind    string   `json:"kind"`
+	Status  string   `json:"status"`
+	Updated string   `json:"updated_at"`
+	Text    string   `json:"text"`
+}
+
+type siteNoteLink struct {
+	Title   string
+	URL     string
+	Domain  string
+	Updated string
+}
+
+type siteGroup struct {
+	Name  string
+	URL   string
+	Count int
+}
+
+type sitePage struct {
+	Title     string
+	Base      string
+	Note      *Note
+	Body      template.HTML
+	Links     []siteNoteLink
+	External  []string
+	Backlinks []siteNoteLink
+	Notes     []siteNoteLink
+	Groups    []siteGroup
+	Sections  []siteSection
+	Search    []siteSearchEntry
+}
+
+type siteSection struct {
+	Title  string
+	URL    string
+	Groups []siteGroup
+}
+
+type noteResolver struct {
+	byID    map[string]NoteFile
+	byTitle map[string]NoteFile
+	byPath  map[string]NoteFile
+	notes   []NoteFile
+}
+
+func newNoteResolver(notes []NoteFile) noteResolver {
+	r := noteResolver{
+		byID:    map[string]NoteFile{},
+		byTitle: map[string]NoteFile{},
+		byPath:  map[string]NoteFile{},
+		notes:   notes,
+	}
+	for _, item := range notes {
+		r.byID[strings.ToUpper(item.Note.ID)] = item
+		title := strings.ToLower(strings.TrimSpace(item.Note.Title))
+		if _, exists := r.byTitle[title]; !exists {
+			r.byTitle[title] = item
+		}
+		r.byPath[item.RelPath] = item
+	}
+	return r
+}
+
+func (r noteResolver) resolve(target string) (NoteFile, bool) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return NoteFile{}, false
+	}
+	if item, ok := r.byPath[strings.TrimPrefix(target, "/")]; ok {
+		return item, true
+	}
+
+	name := strings.TrimSuffix(path.Base(target), ".md")
+	if idx := strings.Index(name, "--"); idx > 0 {
+		if item, ok := r.byID[strings.ToUpper(name[:idx])]; ok {
+			return item, true
+		}
+	}
+	if item, ok := r.byID[strings.ToUpper(name)]; ok {
+		return item, true
+	}
+	if item, ok := r.byTitle[strings.ToLower(name)]; ok {
+		return item, true
+	}
+
+	if len(name) >= 6 {
+		var found NoteFile
+		matches := 0
+		for _, item := range r.notes {
+			if strings.HasPrefix(item.Note.ID, strings.ToUpper(name)) {
+				found = item
+				matches++
+			}
+		}
+		if matches == 1 {
+			return found, true
+		}
+	}
+	return NoteFile{}, false
+}
+
+func (s *Service) ExportSite(outDir string, filter NoteFilter) (SiteExport, error) {
+	result := SiteExport{OutDir: outDir}
+	if strings.TrimSpace(outDir) == "" {
+		return result, errors.New("output directory is required")
+	}
+	if err := prepareSiteDir(outDir); err != nil {
+		return result, err
+	}
+
+	notes, err := s.List(filter, "updated", false)
+	if err != nil {
+		return result, err
+	}
+	resolver := newNoteResolver(notes)
+
+	backlinks := map[string][]NoteFile{}
+	for _, item := range notes {
+		seen := map[string]bool{item.Note.ID: true}
+		targets := append([]string{}, item.Note.Links...)
+		for _, link := range ParseWikiLinks(item.Note.Body) {
+			targets = append(targets, link.Target)
+		}
+		for _, target := range targets {
+			linked, ok := resolver.resolve(target)
+			if !ok || seen[linked.Note.ID] {
+				continue
+			}
+			seen[linked.Note.ID] = true
+			backlinks[linked.Note.ID] = append(backlinks[linked.Note.ID], item)
+		}
+	}
+
+	domains := map[string][]NoteFile{}
+	tags := map[string][]NoteFile{}
+	kinds := map[string][]NoteFile{}
+	search := make([]siteSearchEntry, 0, len(notes))
+
+	for _, item := range notes {
+		note := item.Note
+		page := sitePage{Title: note.Title, Base: "../", Note: &note}
+		page.Body = template.HTML(RenderMarkdownHTML(note.Body, s.siteMarkdownOptions(item, resolver)))
+		for _, target := range note.Links {
+			if linked, ok := resolver.resolve(target); ok {
+				page.Links = append(page.Links, siteLink(linked, "../"))
+				continue
+			}
+			page.External = append(page.External, target)
+		}
+		for _, source := range backlinks[note.ID] {
+			page.Backlinks = append(page.Backlinks, siteLink(source, "../"))
+		}
+		if err := writeSitePage(filepath.Join(outDir, "notes", note.ID+".html"), siteNoteTemplate, page); err != nil {
+			return result, err
+		}
+		result.Pages++
+
+		if note.Domain != "" {
+			domains[note.Domain] = append(domains[note.Domain], item)
+		}
+		for _, tag := range note.Tags {
+			tags[tag] = append(tags[tag], item)
+		}
+		if note.Kind != "" {
+			kinds[note.Kind] = append(kinds[note.Kind], item)
+		}
+		search = append(search, siteSearchEntry{
+			ID:      note.ID,
+			Title:   note.Title,
+			URL:     "notes/" + note.ID + ".html",
+			Domain:  note.Domain,
+			Tags:    append([]string{}, note.Tags...),
+			Kind:    note.Kind,
+			Status:  note.Status,
+			Updated: note.UpdatedAt.Format(time.RFC3339),
+			Text:    note.Body,
+		})
+	}
+	result.Notes = len(notes)
+
+	sections := make([]siteSection, 0, 3)
+	for _, group := range []struct {
+		dir   string
+		title string
+		items map[string][]NoteFile
+	}{
+		{"domains", "Domains", domains},
+		{"tags", "Tags", tags},
+		{"kinds", "Kinds", kinds},
+	} {
+		written, section, err := writeSiteGroups(outDir, group.dir, group.title, group.items)
+		if err != nil {
+			return result, err
+		}
+		result.Pages += written
+		sections = append(sections, section)
+	}
+
+	home := sitePage{Title: "Nitid vault", Base: "", Sections: sections, Search: search}
+	for _, item := range notes {
+		home.Notes = append(home.Notes, siteLink(item, ""))
+	}
+	if err := writeSitePage(filepath.Join(outDir, "index.html"), siteHomeTemplate, home); err != nil {
+		return result, err
+	}
+	result.Pages++
+
+	indexJSON, err := json.MarshalIndent(search, "", "  ")
+	if err != nil {
+		return result, err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, siteIndexFile), append(indexJSON, '\n'), 0o644); err != nil {
+		return result, err
+	}
+
+	assets, err := copyTree(filepath.Join(s.root, "assets"), filepath.Join(outDir, "assets"))
+	if err != nil {
+		return result, err
+	}
+	result.Assets = assets
+
+	return result, nil
+}
+
+func (s *Service) siteMarkdownOptions(item NoteFile, resolver noteResolver) MarkdownOptions {
+	noteDir := path.Dir(item.RelPath)
+	return MarkdownOptions{
+		ResolveWikiLink: func(target string, embed bool) (string, bool) {
+			if target == "" {
+				return "", true
+			}
+			if embed && IsImagePath(target) {
+				asset := path.Join("assets", strings.TrimPrefix(target, "assets/"))
+				if _, err := os.Stat(filepath.Join(s.root, filepath.FromSlash(asset))); err != nil {
+					return "", false
+				}
+				return "../" + asset, true
+			}
+			linked, ok := resolver.resolve(target)
+			if !ok {
+				return "", false
+			}
+			return linked.Note.ID + ".html", true
+		},
+		RewriteURL: func(url string) string {
+			if url == "" || strings.HasPrefix(url, "#") || strings.Contains(url, "://") || strings.HasPrefix(url, "mailto:") {
+				return url
+			}
+			fragment := ""
+			if idx := strings.Index(url, "#"); idx >= 0 {
+				url, fragment = url[:idx], url[idx:]
+			}
+			target := strings.TrimPrefix(url, "/")
+			if !strings.HasPrefix(target, "assets/") && !strings.HasPrefix(url, "/") {
+				target = path.Clean(path.Join(noteDir, url))
+			}
+			if strings.HasPrefix(target, "assets/") {
+				return "../" + target + fragment
+			}
+			if linked, ok := resolver.resolve(target); ok {
+				return linked.Note.ID + ".html" + fragment
+			}
+			return url + fragment
+		},
+	}
+}
+
+func siteLink(item NoteFile, base string) siteNoteLink {
+	return siteNoteLink{
+		Title:   item.Note.Title,
+		URL:     base + "notes/" + item.Note.ID + ".html",
+		Domain:  item.Note.Domain,
+		Updated: item.Note.UpdatedAt.Format("2006-01-02"),
+	}
+}
+
+func writeSiteGroups(outDir, dir, title string, items map[string][]NoteFile) (int, siteSection, error) {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	section := siteSection{Title: title, URL: dir + "/index.html"}
+	written := 0
+	for _, name := range names {
+		page := sitePage{Title: fmt.Sprintf("%s: %s", strings.TrimSuffix(title, "s"), name), Base: "../"}
+		for _, item := range items[name] {
+			page.Notes = append(page.Notes, siteLink(item, "../"))
+		}
+		if err := writeSitePage(filepath.Join(outDir, dir, name+".html"), siteListTemplate, page); err != nil {
+			return written, section, err
+		}
+		written++
+		section.Groups = append(section.Groups, siteGroup{Name: name, URL: dir + "/" + name + ".html", Count: len(items[name])})
+	}
+
+	page := sitePage{Title: title, Base: "../"}
+	for _, group := range section.Groups {
+		page.Groups = append(page.Groups, siteGroup{Name: group.Name, URL: path.Base(group.URL), Count: group.Count})
+	}
+	if err := writeSitePage(filepath.Join(outDir, dir, "index.html"), siteListTemplate, page); err != nil {
+		return written, section, err
+	}
+	return written + 1, section, nil
+}
+
+func prepareSiteDir(outDir string) error {
+	entries, err := os.ReadDir(outDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(outDir, 0o755)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(outDir, siteIndexFile)); err != nil {
+		return fmt.Errorf("output directory %s is not empty and does not contain a previous export", outDir)
+	}
+	for _, name := range siteGeneratedEntries {
+		if err := os.RemoveAll(filepath.Join(outDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSitePage(target string, tmpl *template.Template, page sitePage) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(file, page); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func copyTree(src, dst string) (int, error) {
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	copied := 0
+	err := filepath.WalkDir(src, func(current string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(src, current)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if err := copyFile(current, target); err != nil {
+			return err
+		}
+		copied++
+		return nil
+	})
+	return copied, err
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+const siteLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 52rem; margin: 0 auto; padding: 1rem 1.5rem 3rem; line-height: 1.55; color: #1f2328; }
+nav { border-bottom: 1px solid #d0d7de; padding-bottom: .5rem; margin-bottom: 1rem; }
+nav a { margin-right: 1rem; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+.meta { color: #57606a; font-size: .9rem; }
+.meta span { margin-right: 1rem; }
+.tag { background: #eef1f4; border-radius: .3rem; padding: 0 .35rem; margin-right: .25rem; }
+.wikilink.missing { color: #cf222e; border-bottom: 1px dashed #cf222e; }
+pre { background: #f6f8fa; padding: .75rem; overflow-x: auto; border-radius: .4rem; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: .9em; }
+blockquote { border-left: 3px solid #d0d7de; margin-left: 0; padding-left: 1rem; color: #57606a; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: .3rem .6rem; }
+li.task { list-style: none; margin-left: -1.2rem; }
+img { max-width: 100%; }
+ul.notes li { margin: .2rem 0; }
+ul.notes .meta { margin-left: .5rem; }
+#search { width: 100%; padding: .5rem; font-size: 1rem; box-sizing: border-box; }
+section.links { border-top: 1px solid #d0d7de; margin-top: 2rem; }
+</style>
+</head>
+<body>
+<nav><a href="{{.Base}}index.html">Home</a><a href="{{.Base}}domains/index.html">Domains</a><a href="{{.Base}}tags/index.html">Tags</a><a href="{{.Base}}kinds/index.html">Kinds</a></nav>
+{{template "content" .}}
+</body>
+</html>
+{{define "notelist"}}<ul class="notes">
+{{range .}}<li><a href="{{.URL}}">{{.Title}}</a><span class="meta">{{if .Domain}}{{.Domain}} · {{end}}{{.Updated}}</span></li>
+{{end}}</ul>{{end}}
+`
+
+var (
+	siteNoteTemplate = template.Must(template.Must(template.New("page").Parse(siteLayout)).Parse(`{{define "content"}}{{$base := .Base}}
+<article>
+<h1>{{.Note.Title}}</h1>
+<p class="meta"><span>{{.Note.ID}}</span>{{if .Note.Domain}}<span><a href="{{$base}}domains/{{.Note.Domain}}.html">{{.Note.Domain}}</a></span>{{end}}{{if .Note.Kind}}<span><a href="{{$base}}kinds/{{.Note.Kind}}.html">{{.Note.Kind}}</a></span>{{end}}<span>{{.Note.Status}}</span><span>created {{.Note.CreatedAt.Format "2006-01-02"}}</span><span>updated {{.Note.UpdatedAt.Format "2006-01-02"}}</span></p>
+{{if .Note.Tags}}<p class="meta">{{range .Note.Tags}}<a class="tag" href="{{$base}}tags/{{.}}.html">#{{.}}</a>{{end}}</p>{{end}}
+{{.Body}}
+</article>
+{{if or .Links .External}}<section class="links">
+<h2>Links</h2>
+{{template "notelist" .Links}}
+{{if .External}}<ul>{{range .External}}<li>{{.}}</li>{{end}}</ul>{{end}}
+</section>{{end}}
+<section class="links">
+<h2>Backlinks</h2>
+{{if .Backlinks}}{{template "notelist" .Backlinks}}{{else}}<p class="meta">No notes link here.</p>{{end}}
+</section>
+{{end}}`))
+
+	siteListTemplate = template.Must(template.Must(template.New("page").Parse(siteLayout)).Parse(`{{define "content"}}
+<h1>{{.Title}}</h1>
+{{if .Groups}}<ul>{{range .Groups}}<li><a href="{{.URL}}">{{.Name}}</a> <span class="meta">({{.Count}})</span></li>{{end}}</ul>{{end}}
+{{if .Notes}}{{template "notelist" .Notes}}{{end}}
+{{end}}`))
+
+	siteHomeTemplate = template.Must(template.Must(template.New("page").Parse(siteLayout)).Parse(`{{define "content"}}
+<h1>{{.Title}}</h1>
+<input id="search" type="search" placeholder="Search notes" autocomplete="off">
+<ul id="results" class="notes"></ul>
+{{range .Sections}}{{if .Groups}}<h2><a href="{{.URL}}">{{.Title}}</a></h2>
+<p>{{range .Groups}}<a class="tag" href="{{.URL}}">{{.Name}} ({{.Count}})</a> {{end}}</p>{{end}}{{end}}
+<h2>All notes</h2>
+{{template "notelist" .Notes}}
+<script type="application/json" id="search-index">{{.Search}}</script>
+<script>
+(function () {
+  var index = JSON.parse(document.getElementById("search-index").textContent) || [];
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.textContent = "";
+    if (terms.length === 0) { return; }
+    index.filter(function (entry) {
+      var haystack = [entry.title, entry.domain, entry.kind, entry.tags.join(" "), entry.text].join(" ").toLowerCase();
+      return terms.every(function (term) { return haystack.indexOf(term) !== -1; });
+    }).slice(0, 50).forEach(function (entry) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = entry.url;
+      a.textContent = entry.title;
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+  });
+})();
+</script>
+{{end}}`))
+)
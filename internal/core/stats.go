@@ -0,0 +1,182 @@
+package core
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+type Count struct {
+	Name  string
+	Count int
+}
+
+type WeekCount struct {
+	Label string
+	Start time.Time
+	Count int
+}
+
+type NoteSize struct {
+	Note  NoteFile
+	Bytes int64
+	Words int
+}
+
+type TagPair struct {
+	Tags  [2]string
+	Count int
+}
+
+type InboxStats struct {
+	Count  int
+	P50    time.Duration
+	P90    time.Duration
+	Oldest time.Duration
+}
+
+type Stats struct {
+	Total        int
+	Statuses     []Count
+	Kinds        []Count
+	Domains      []Count
+	Tags         []Count
+	Weekly       []WeekCount
+	Inbox        InboxStats
+	Largest      []NoteSize
+	NeverUpdated []NoteFile
+	NeverCount   int
+	TagPairs     []TagPair
+}
+
+func (s *Service) Stats(filter NoteFilter, now time.Time, weeks, top int) (Stats, error) {
+	notes, err := vault.ListNotes(s.root, filter)
+	if err != nil {
+		return Stats{}, err
+	}
+	SortNotes(notes, "created", true)
+
+	stats := Stats{Total: len(notes)}
+	statuses, kinds, domains, tags := map[string]int{}, map[string]int{}, map[string]int{}, map[string]int{}
+	pairs := map[[2]string]int{}
+	perWeek := map[string]int{}
+	inboxAges := make([]time.Duration, 0)
+	sizes := make([]NoteSize, 0, len(notes))
+
+	for _, item := range notes {
+		note := item.Note
+		statuses[note.Status]++
+		kinds[note.Kind]++
+		domains[note.Domain]++
+		for _, tag := range note.Tags {
+			tags[tag]++
+		}
+		unique := uniqueSortedStrings(note.Tags)
+		for i := 0; i < len(unique); i++ {
+			for j := i + 1; j < len(unique); j++ {
+				pairs[[2]string{unique[i], unique[j]}]++
+			}
+		}
+		if period, err := PeriodFor("weekly", note.CreatedAt.UTC()); err == nil {
+			perWeek[period.Label]++
+		}
+		if note.Status == vault.StatusInbox {
+			inboxAges = append(inboxAges, now.Sub(note.CreatedAt))
+		}
+		if !note.UpdatedAt.Truncate(time.Second).After(note.CreatedAt.Truncate(time.Second)) {
+			stats.NeverCount++
+			if len(stats.NeverUpdated) < top {
+				stats.NeverUpdated = append(stats.NeverUpdated, item)
+			}
+		}
+		size := NoteSize{Note: item, Words: len(strings.Fields(note.Body))}
+		if info, err := os.Stat(item.Path); err == nil {
+			size.Bytes = info.Size()
+		}
+		sizes = append(sizes, size)
+	}
+
+	stats.Statuses = sortedCounts(statuses)
+	stats.Kinds = sortedCounts(kinds)
+	stats.Domains = sortedCounts(domains)
+	stats.Tags = sortedCounts(tags)
+
+	current, err := PeriodFor("weekly", now.UTC())
+	if err != nil {
+		return Stats{}, err
+	}
+	for i := weeks - 1; i >= 0; i-- {
+		period, _ := PeriodFor("weekly", current.Start.AddDate(0, 0, -7*i))
+		stats.Weekly = append(stats.Weekly, WeekCount{Label: period.Label, Start: period.Start, Count: perWeek[period.Label]})
+	}
+
+	sort.Slice(inboxAges, func(i, j int) bool { return inboxAges[i] < inboxAges[j] })
+	stats.Inbox = InboxStats{Count: len(inboxAges)}
+	if len(inboxAges) > 0 {
+		stats.Inbox.P50 = percentile(inboxAges, 50)
+		stats.Inbox.P90 = percentile(inboxAges, 90)
+		stats.Inbox.Oldest = inboxAges[len(inboxAges)-1]
+	}
+
+	sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].Bytes > sizes[j].Bytes })
+	if len(sizes) > top {
+		sizes = sizes[:top]
+	}
+	stats.Largest = sizes
+
+	for pair, count := range pairs {
+		stats.TagPairs = append(stats.TagPairs, TagPair{Tags: pair, Count: count})
+	}
+	sort.Slice(stats.TagPairs, func(i, j int) bool {
+		a, b := stats.TagPairs[i], stats.TagPairs[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Tags[0] != b.Tags[0] {
+			return a.Tags[0] < b.Tags[0]
+		}
+		return a.Tags[1] < b.Tags[1]
+	})
+	if len(stats.TagPairs) > top {
+		stats.TagPairs = stats.TagPairs[:top]
+	}
+	return stats, nil
+}
+
+func sortedCounts(values map[string]int) []Count {
+	counts := make([]Count, 0, len(values))
+	for name, count := range values {
+		counts = append(counts, Count{Name: name, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+func uniqueSortedStrings(values []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			out = append(out, value)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+func TestStats(t *testing.T) {
+	now := time.Date(2026, 3, 18, 12, 0, 0, 0, time.UTC)
+	svc, _ := newTestService(t,
+		Note{Title: "A", Status: vault.StatusInbox, CreatedAt: now.AddDate(0, 0, -1), Tags: []string{"go", "testing"}, Body: "one two three"},
+		Note{Title: "B", Status: vault.StatusInbox, CreatedAt: now.AddDate(0, 0, -3), Tags: []string{"go"}},
+		Note{Title: "C", Status: vault.StatusInbox, CreatedAt: now.AddDate(0, 0, -10)},
+		Note{Title: "D", Status: vault.StatusActive, Domain: "engineering", CreatedAt: now.AddDate(0, 0, -15), UpdatedAt: now.AddDate(0, 0, -15).Add(time.Hour), Tags: []string{"testing", "go", "ci"}, Body: "a much longer body with plenty of words in it to be the largest note"},
+		Note{Title: "E", Status: vault.StatusArchived, Domain: "engineering", CreatedAt: now.AddDate(0, 0, -40), Tags: []string{"ci"}},
+	)
+
+	stats, err := svc.Stats(NoteFilter{}, now, 4, 2)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Total != 5 {
+		t.Fatalf("expected 5 notes, got %d", stats.Total)
+	}
+	if want := []Count{{"inbox", 3}, {"active", 1}, {"archived", 1}}; !reflect.DeepEqual(stats.Statuses, want) {
+		t.Fatalf("statuses: %+v", stats.Statuses)
+	}
+	if want := []Count{{"", 3}, {"engineering", 2}}; !reflect.DeepEqual(stats.Domains, want) {
+		t.Fatalf("domains: %+v", stats.Domains)
+	}
+	if want := []Count{{"go", 3}, {"ci", 2}, {"testing", 2}}; !reflect.DeepEqual(stats.Tags, want) {
+		t.Fatalf("tags: %+v", stats.Tags)
+	}
+
+	weeks := make([]string, 0, len(stats.Weekly))
+	for _, week := range stats.Weekly {
+		weeks = append(weeks, week.Label+"="+strconv.Itoa(week.Count))
+	}
+	if want := []string{"2026-W09=0", "2026-W10=2", "2026-W11=1", "2026-W12=1"}; !reflect.DeepEqual(weeks, want) {
+		t.Fatalf("weekly: %v", weeks)
+	}
+
+	if stats.Inbox.Count != 3 || stats.Inbox.P50 != 72*time.Hour || stats.Inbox.P90 != 240*time.Hour || stats.Inbox.Oldest != 240*time.Hour {
+		t.Fatalf("inbox: %+v", stats.Inbox)
+	}
+	if len(stats.Largest) != 2 || stats.Largest[0].Note.Note.Title != "D" || stats.Largest[0].Words != 15 {
+		t.Fatalf("largest: %+v", stats.Largest)
+	}
+	if stats.NeverCount != 4 || len(stats.NeverUpdated) != 2 || stats.NeverUpdated[0].Note.Title != "E" {
+		t.Fatalf("never updated: %d %+v", stats.NeverCount, stats.NeverUpdated)
+	}
+	if want := []TagPair{{[2]string{"go", "testing"}, 2}, {[2]string{"ci", "go"}, 1}}; !reflect.DeepEqual(stats.TagPairs, want) {
+		t.Fatalf("tag pairs: %+v", stats.TagPairs)
+	}
+}
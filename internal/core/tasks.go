@@ -0,0 +1,241 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+var (
+	taskPattern      = regexp.MustCompile(`^(\s*[-*+] \[)([ xX])(\]\s+)(\S.*)$`)
+	taskDuePattern   = regexp.MustCompile(`(?:^|\s)due:(\S+)`)
+	taskOwnerPattern = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9][A-Za-z0-9_.-]*)`)
+	taskPrioPattern  = regexp.MustCompile(`(?:^|\s)!(high|medium|low)\b`)
+)
+
+var ErrTaskNotFound = errors.New("task not found")
+
+type Task struct {
+	Note     NoteFile
+	Line     int
+	Text     string
+	Done     bool
+	Heading  string
+	Due      time.Time
+	Owners   []string
+	Priority string
+}
+
+type TaskFilter struct {
+	Open     bool
+	Done     bool
+	Owner    string
+	Priority string
+}
+
+func ParseTasks(body string, reference time.Time) []Task {
+	tasks := make([]Task, 0)
+	heading := ""
+	inFence := false
+	for idx, line := range strings.Split(body, "\n") {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := headingPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			heading = strings.TrimSpace(match[2])
+			continue
+		}
+		match := taskPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(match[4])
+		task := Task{
+			Line:    idx + 1,
+			Text:    text,
+			Done:    match[2] != " ",
+			Heading: heading,
+			Owners:  make([]string, 0),
+		}
+		if due := taskDuePattern.FindStringSubmatch(text); due != nil {
+			if parsed, err := ParseDate(due[1], reference.UTC()); err == nil {
+				task.Due = parsed
+			}
+		}
+		for _, owner := range taskOwnerPattern.FindAllStringSubmatch(text, -1) {
+			task.Owners = append(task.Owners, strings.ToLower(owner[1]))
+		}
+		if prio := taskPrioPattern.FindStringSubmatch(strings.ToLower(text)); prio != nil {
+			task.Priority = prio[1]
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func ResolveTaskDates(body string, reference time.Time) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && taskPattern.MatchString(line) {
+			lines[i] = ResolveDue(line, reference)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func ResolveDue(text string, reference time.Time) string {
+	return taskDuePattern.ReplaceAllStringFunc(text, func(token string) string {
+		prefix, value, _ := strings.Cut(token, "due:")
+		if _, err := time.Parse("2006-01-02", value); err == nil {
+			return token
+		}
+		parsed, err := ParseDate(value, reference.UTC())
+		if err != nil {
+			return token
+		}
+		return prefix + "due:" + parsed.Format("2006-01-02")
+	})
+}
+
+func (t Task) UID() string {
+	text := taskDuePattern.ReplaceAllString(t.Text, " ")
+	text = taskOwnerPattern.ReplaceAllString(text, " ")
+	text = taskPrioPattern.ReplaceAllString(text, " ")
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(text), " ")))
+	return t.Note.Note.ID + "-" + hex.EncodeToString(sum[:8])
+}
+
+func (t Task) Ref(prefix string) string {
+	if prefix == "" {
+		prefix = t.Note.Note.ID
+	}
+	return fmt.Sprintf("%s:%d", prefix, t.Line)
+}
+
+func (t Task) HasOwner(owner string) bool {
+	owner = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(owner), "@"))
+	for _, candidate := range t.Owners {
+		if candidate == owner {
+			return true
+		}
+	}
+	return false
+}
+
+func (f TaskFilter) Matches(task Task) bool {
+	if f.Open && !f.Done && task.Done {
+		return false
+	}
+	if f.Done && !f.Open && !task.Done {
+		return false
+	}
+	if f.Owner != "" && !task.HasOwner(f.Owner) {
+		return false
+	}
+	if f.Priority != "" && task.Priority != strings.ToLower(strings.TrimPrefix(f.Priority, "!")) {
+		return false
+	}
+	return true
+}
+
+func (s *Service) Tasks(filter NoteFilter, taskFilter TaskFilter) ([]Task, error) {
+	notes, err := s.List(filter, "created", true)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]Task, 0)
+	for _, item := range notes {
+		for _, task := range noteTasks(item) {
+			if taskFilter.Matches(task) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func noteTasks(item NoteFile) []Task {
+	tasks := ParseTasks(item.Note.Body, item.Note.CreatedAt)
+	offset := 0
+	if len(tasks) > 0 {
+		offset, _ = vault.BodyLineOffset(item.Path)
+	}
+	for i := range tasks {
+		tasks[i].Note = item
+		tasks[i].Line += offset
+	}
+	return tasks
+}
+
+func ParseTaskRef(ref string) (string, int, error) {
+	idx := strings.LastIndex(ref, ":")
+	if idx <= 0 || idx == len(ref)-1 {
+		return "", 0, fmt.Errorf("invalid task ref %q: use <id|@ref>:<line>", ref)
+	}
+	line, err := strconv.Atoi(ref[idx+1:])
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("invalid task ref %q: use <id|@ref>:<line>", ref)
+	}
+	return strings.TrimSpace(ref[:idx]), line, nil
+}
+
+func (s *Service) CompleteTask(ref string, now time.Time) (Task, MutationResult, error) {
+	selector, line, err := ParseTaskRef(ref)
+	if err != nil {
+		return Task{}, MutationResult{}, err
+	}
+	noteFile, err := s.FindBySelector(selector)
+	if err != nil {
+		return Task{}, MutationResult{}, err
+	}
+
+	var task Task
+	found := false
+	for _, candidate := range noteTasks(noteFile) {
+		if candidate.Line == line {
+			task, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return Task{}, MutationResult{}, fmt.Errorf("%w: %s has no task on line %d", ErrTaskNotFound, noteFile.RelPath, line)
+	}
+	if task.Done {
+		return task, MutationResult{}, fmt.Errorf("task already done: %s", task.Text)
+	}
+
+	offset, err := vault.BodyLineOffset(noteFile.Path)
+	if err != nil {
+		return Task{}, MutationResult{}, err
+	}
+	lines := strings.Split(noteFile.Note.Body, "\n")
+	lines[line-offset-1] = taskPattern.ReplaceAllString(lines[line-offset-1], "${1}x${3}${4}")
+	note := noteFile.Note
+	note.Body = strings.Join(lines, "\n")
+	note.UpdatedAt = now.UTC()
+
+	rel, err := vault.SaveNote(s.root, noteFile.Path, note)
+	if err != nil {
+		return Task{}, MutationResult{}, err
+	}
+	task.Done = true
+	task.Note.Note = note
+	task.Note.RelPath = rel
+	return task, MutationResult{NoteID: note.ID, RelPath: rel}, nil
+}
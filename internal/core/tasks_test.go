@@ -0,0 +1,189 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+func TestParseTasks(t *testing.T) {
+	body := strings.Join([]string{
+		"Intro line",
+		"- [ ] Loose task",
+		"",
+		"## Plan",
+		"",
+		"- [ ] Ship importer due:2026-10-20 @ana !high",
+		"  * [X] Nested done @Bo @ana",
+		"- [] not a task",
+		"```",
+		"- [ ] inside fence",
+		"```",
+		"### Later",
+		"+ [ ] Email ops@example.com due:someday !urgent",
+		"- [ ] Book venue due:next-friday",
+	}, "\n")
+
+	tasks := ParseTasks(body, time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	if len(tasks) != 5 {
+		t.Fatalf("expected 5 tasks, got %d: %+v", len(tasks), tasks)
+	}
+
+	first := tasks[0]
+	if first.Line != 2 || first.Heading != "" || first.Done || first.Text != "Loose task" {
+		t.Fatalf("unexpected first task: %+v", first)
+	}
+
+	plan := tasks[1]
+	if plan.Line != 6 || plan.Heading != "Plan" || plan.Priority != "high" || plan.Due.Format("2006-01-02") != "2026-10-20" {
+		t.Fatalf("unexpected plan task: %+v", plan)
+	}
+	if !reflect.DeepEqual(plan.Owners, []string{"ana"}) {
+		t.Fatalf("unexpected owners: %v", plan.Owners)
+	}
+
+	nested := tasks[2]
+	if !nested.Done || nested.Line != 7 || !reflect.DeepEqual(nested.Owners, []string{"bo", "ana"}) {
+		t.Fatalf("unexpected nested task: %+v", nested)
+	}
+
+	later := tasks[3]
+	if later.Heading != "Later" || !later.Due.IsZero() || later.Priority != "" || len(later.Owners) != 0 {
+		t.Fatalf("unexpected later task: %+v", later)
+	}
+	if due := tasks[4].Due.Format("2006-01-02"); due != "2026-03-06" {
+		t.Fatalf("relative due date should resolve from the reference day, got %s", due)
+	}
+}
+
+func TestResolveTaskDates(t *testing.T) {
+	body := strings.Join([]string{
+		"Meet due:tomorrow in prose",
+		"- [ ] Call vendor due:tomorrow @ana",
+		"- [ ] Review due:-3d",
+		"- [x] Fixed due:2026-04-01",
+		"- [ ] Unknown due:someday",
+		"```",
+		"- [ ] Example due:tomorrow",
+		"```",
+	}, "\n")
+	want := strings.Join([]string{
+		"Meet due:tomorrow in prose",
+		"- [ ] Call vendor due:2026-10-17 @ana",
+		"- [ ] Review due:2026-10-13",
+		"- [x] Fixed due:2026-04-01",
+		"- [ ] Unknown due:someday",
+		"```",
+		"- [ ] Example due:tomorrow",
+		"```",
+	}, "\n")
+	if got := ResolveTaskDates(body, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)); got != want {
+		t.Fatalf("unexpected resolved body:\n%s", got)
+	}
+
+	root := t.TempDir()
+	svc := New(root)
+	created := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	note := Note{ID: vault.NewULID(created), Title: "Daily", CreatedAt: created, UpdatedAt: created, Status: vault.StatusActive, Kind: "daily", Body: "- [ ] Ship due:tomorrow"}
+	rel, err := svc.Create(note)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	content, err := os.ReadFile(root + "/" + rel)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(content), "- [ ] Ship due:2026-10-17") {
+		t.Fatalf("relative due date not written as absolute:\n%s", content)
+	}
+}
+
+func TestTaskFilterAndRef(t *testing.T) {
+	task := Task{Line: 4, Done: false, Owners: []string{"ana"}, Priority: "high"}
+	cases := []struct {
+		filter TaskFilter
+		want   bool
+	}{
+		{TaskFilter{}, true},
+		{TaskFilter{Open: true}, true},
+		{TaskFilter{Done: true}, false},
+		{TaskFilter{Open: true, Done: true}, true},
+		{TaskFilter{Owner: "@Ana"}, true},
+		{TaskFilter{Owner: "bo"}, false},
+		{TaskFilter{Priority: "!high"}, true},
+		{TaskFilter{Priority: "low"}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.filter.Matches(task); got != tc.want {
+			t.Fatalf("filter %+v: got %v, want %v", tc.filter, got, tc.want)
+		}
+	}
+
+	selector, line, err := ParseTaskRef("@2:14")
+	if err != nil || selector != "@2" || line != 14 {
+		t.Fatalf("unexpected ref parse: %q %d %v", selector, line, err)
+	}
+	for _, bad := range []string{"01ABC", "01ABC:", ":3", "01ABC:0", "01ABC:x"} {
+		if _, _, err := ParseTaskRef(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestCompleteTask(t *testing.T) {
+	root := t.TempDir()
+	if err := vault.CreateVaultStructure(root); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	note := Note{
+		ID:        vault.NewULID(created),
+		Title:     "Sprint",
+		CreatedAt: created,
+		UpdatedAt: created,
+		Status:    vault.StatusInbox,
+		Kind:      "note",
+		Body:      "## Plan\n\n- [ ] Write docs @ana\n- [x] Ship it",
+	}
+	svc := New(root)
+	rel, err := svc.Create(note)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	offset, err := vault.BodyLineOffset(root + "/" + rel)
+	if err != nil {
+		t.Fatalf("offset: %v", err)
+	}
+	ref := func(line int) string { return fmt.Sprintf("%s:%d", note.ID, offset+line) }
+
+	now := created.Add(time.Hour)
+	task, result, err := svc.CompleteTask(ref(3), now)
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if !task.Done || task.Heading != "Plan" || task.Line != offset+3 || result.NoteID != note.ID {
+		t.Fatalf("unexpected completion: %+v %+v", task, result)
+	}
+
+	content, err := os.ReadFile(root + "/" + result.RelPath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(content), "- [x] Write docs @ana\n- [x] Ship it") {
+		t.Fatalf("task not ticked:\n%s", content)
+	}
+
+	if _, _, err := svc.CompleteTask(ref(4), now); err == nil || !strings.Contains(err.Error(), "already done") {
+		t.Fatalf("expected already done error, got %v", err)
+	}
+	for _, missing := range []string{ref(1), note.ID + ":3"} {
+		if _, _, err := svc.CompleteTask(missing, now); err == nil {
+			t.Fatalf("expected missing task error for %s", missing)
+		}
+	}
+}
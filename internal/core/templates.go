@@ -0,0 +1,258 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"nitid/internal/vault"
+)
+
+const TemplateSourceBuiltin = "built-in"
+
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*(?:prompt\s+"([^"]*)"|([a-z][a-z0-9_.]*))\s*\}\}`)
+
+type Template struct {
+	Name      string
+	Kind      string
+	Title     string
+	Domain    string
+	Tags      []string
+	Body      string
+	Source    string
+	Overrides bool
+}
+
+func BuiltinTemplates() map[string]Template {
+	return map[string]Template{
+		"note": {
+			Kind: "note",
+		},
+		"adr": {
+			Kind:  "adr",
+			Title: "Architecture decision",
+			Body:  "## Context\n\n## Decision\n\n## Consequences\n",
+		},
+		"meeting": {
+			Kind:  "note",
+			Title: "Meeting notes",
+			Body:  "## Date\n\n{{date}} {{time}}\n\n## Attendees\n\n{{prompt \"Attendees\"}}\n\n## Notes\n\n## Action items\n- [ ] ",
+		},
+		"bug": {
+			Kind:  "note",
+			Title: "Bug report",
+			Body:  "## Symptoms\n\n## Steps to reproduce\n\n## Root cause\n\n## Fix\n\n## Validation\n",
+		},
+	}
+}
+
+func (s *Service) TemplatesDir() string {
+	return filepath.Join(s.root, ".nitid", "templates")
+}
+
+func (s *Service) Templates() (map[string]Template, []string, error) {
+	templates := map[string]Template{}
+	for name, tmpl := range BuiltinTemplates() {
+		tmpl.Name = name
+		tmpl.Source = TemplateSourceBuiltin
+		templates[name] = tmpl
+	}
+
+	entries, err := os.ReadDir(s.TemplatesDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return templates, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		path := filepath.Join(s.TemplatesDir(), entry.Name())
+		rel := toRelOrAbs(s.root, path)
+		tmpl, problems, err := readTemplate(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		for _, problem := range problems {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", rel, problem))
+		}
+		tmpl.Source = rel
+		_, tmpl.Overrides = templates[tmpl.Name]
+		templates[tmpl.Name] = tmpl
+	}
+	sort.Strings(warnings)
+	return templates, warnings, nil
+}
+
+func (s *Service) Template(name string) (Template, []string, error) {
+	templates, warnings, err := s.Templates()
+	if err != nil {
+		return Template{}, nil, err
+	}
+	tmpl, ok := templates[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Template{}, warnings, fmt.Errorf("unknown template %q", name)
+	}
+	return tmpl, warnings, nil
+}
+
+func (s *Service) NewTemplate(name string, from Template) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !vault.IsValidDomainID(name) {
+		return "", fmt.Errorf("invalid template name %q: use lowercase kebab-case", name)
+	}
+	path := filepath.Join(s.TemplatesDir(), name+".md")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("template already exists: %s", toRelOrAbs(s.root, path))
+	}
+	if err := os.MkdirAll(s.TemplatesDir(), 0o755); err != nil {
+		return "", err
+	}
+	if from.Kind == "" {
+		from.Kind = "note"
+	}
+	if err := os.WriteFile(path, []byte(RenderTemplateFile(from)), 0o644); err != nil {
+		return "", err
+	}
+	return toRelOrAbs(s.root, path), nil
+}
+
+func RenderTemplateFile(tmpl Template) string {
+	tags := make([]string, 0, len(tmpl.Tags))
+	for _, tag := range tmpl.Tags {
+		tags = append(tags, fmt.Sprintf("%q", tag))
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString(fmt.Sprintf("kind: %q\n", tmpl.Kind))
+	b.WriteString(fmt.Sprintf("title: %q\n", tmpl.Title))
+	b.WriteString(fmt.Sprintf("domain: %q\n", tmpl.Domain))
+	b.WriteString(fmt.Sprintf("tags: [%s]\n", strings.Join(tags, ", ")))
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(tmpl.Body))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func readTemplate(path string) (Template, []string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Template{}, nil, err
+	}
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if !vault.IsValidDomainID(name) {
+		return Template{}, nil, fmt.Errorf("invalid template name %q: use lowercase kebab-case file names", name)
+	}
+
+	properties, body, err := splitOptionalFrontmatter(content)
+	if err != nil {
+		return Template{}, nil, err
+	}
+
+	tmpl := Template{Name: name, Kind: "note", Body: strings.TrimSpace(body)}
+	problems := make([]string, 0)
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := properties[key]
+		switch key {
+		case "kind":
+			kind, _ := value.(string)
+			if !vault.IsAllowedKind(strings.TrimSpace(kind)) {
+				return Template{}, nil, fmt.Errorf("invalid kind %v", value)
+			}
+			tmpl.Kind = strings.TrimSpace(kind)
+		case "title":
+			title, _ := value.(string)
+			tmpl.Title = strings.TrimSpace(title)
+		case "domain":
+			domain, _ := value.(string)
+			domain = strings.TrimSpace(domain)
+			if domain != "" && !vault.IsValidDomainID(domain) {
+				return Template{}, nil, fmt.Errorf("invalid domain %q: use lowercase kebab-case", domain)
+			}
+			tmpl.Domain = domain
+		case "tags":
+			tmpl.Tags = propertyTags(value)
+		default:
+			problems = append(problems, fmt.Sprintf("unknown template property %q ignored", key))
+		}
+	}
+	tmpl.Tags = vault.SanitizeTags(tmpl.Tags)
+	return tmpl, problems, nil
+}
+
+func ExpandTemplate(text string, lookup func(name string, prompt bool) (string, bool, error)) (string, error) {
+	var expandErr error
+	out := templateVariablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+		groups := templateVariablePattern.FindStringSubmatch(match)
+		name, prompt := groups[2], false
+		if name == "" {
+			name, prompt = strings.TrimSpace(groups[1]), true
+		}
+		value, ok, err := lookup(name, prompt)
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		if !ok {
+			return match
+		}
+		return value
+	})
+	return out, expandErr
+}
+
+func TemplatePrompts(text string) []string {
+	prompts := make([]string, 0)
+	seen := map[string]bool{}
+	for _, groups := range templateVariablePattern.FindAllStringSubmatch(text, -1) {
+		label := strings.TrimSpace(groups[1])
+		if groups[2] != "" || seen[strings.ToLower(label)] {
+			continue
+		}
+		seen[strings.ToLower(label)] = true
+		prompts = append(prompts, label)
+	}
+	return prompts
+}
+
+func CurrentUser() string {
+	for _, key := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if name := strings.TrimSpace(os.Getenv(key)); name != "" {
+			return name
+		}
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return ""
+}
+
+func GitBranch(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	lookup := func(name string, prompt bool) (string, bool, error) {
+		switch {
+		case prompt && name == "Fail":
+			return "", false, errors.New("no answer")
+		case prompt:
+			return "<" + name + ">", true, nil
+		case name == "date":
+			return "2026-03-01", true, nil
+		case name == "git.branch":
+			return "main", true, nil
+		default:
+			return "", false, nil
+		}
+	}
+
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "variables", text: "## {{date}} on {{ git.branch }}", want: "## 2026-03-01 on main"},
+		{name: "prompt", text: `Attendees: {{prompt "Attendees"}}`, want: "Attendees: <Attendees>"},
+		{name: "unknown kept", text: "{{.Title}} {{unknown}} {date}", want: "{{.Title}} {{unknown}} {date}"},
+		{name: "plain", text: "no placeholders", want: "no placeholders"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ExpandTemplate(tc.text, lookup)
+			if err != nil {
+				t.Fatalf("expand: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := ExpandTemplate(`{{prompt "Fail"}}`, lookup); err == nil {
+		t.Fatalf("expected lookup error to be returned")
+	}
+}
+
+func TestTemplatePrompts(t *testing.T) {
+	got := TemplatePrompts(`{{prompt "Attendees"}} {{date}} {{prompt "Goal"}} {{prompt "attendees"}}`)
+	want := []string{"Attendees", "Goal"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"strings"
+)
+
+const maxInferredTitle = 72
+
+func InferTitle(body string) string {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "Untitled"
+	}
+
+	line := []rune(strings.TrimSpace(strings.Split(body, "\n")[0]))
+	if len(line) <= maxInferredTitle {
+		return string(line)
+	}
+	return strings.TrimSpace(string(line[:maxInferredTitle]))
+}
+
+func FirstHeading(body string) string {
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := headingPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			if title := strings.TrimSpace(match[2]); title != "" {
+				return title
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+type TriageState struct {
+	Started  time.Time `json:"started"`
+	Seen     []string  `json:"seen"`
+	Moved    int       `json:"moved"`
+	Tagged   int       `json:"tagged"`
+	Archived int       `json:"archived"`
+	Deleted  int       `json:"deleted"`
+	Skipped  int       `json:"skipped"`
+}
+
+func (state *TriageState) MarkSeen(id string) {
+	state.Seen = append(state.Seen, id)
+}
+
+func (s *Service) TriagePath() string {
+	return filepath.Join(s.root, ".nitid", "triage.json")
+}
+
+func (s *Service) LoadTriage() (TriageState, bool, error) {
+	content, err := os.ReadFile(s.TriagePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return TriageState{}, false, nil
+	}
+	if err != nil {
+		return TriageState{}, false, err
+	}
+	var state TriageState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return TriageState{}, false, errors.New("triage progress is unreadable; run ntd triage --restart")
+	}
+	return state, true, nil
+}
+
+func (s *Service) SaveTriage(state TriageState) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.TriagePath() + ".tmp"
+	if err := os.WriteFile(tmp, append(content, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.TriagePath())
+}
+
+func (s *Service) ClearTriage() error {
+	if err := os.Remove(s.TriagePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) TriageQueue(state TriageState) ([]NoteFile, error) {
+	notes, err := vault.ListNotes(s.root, NoteFilter{Status: vault.StatusInbox})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(state.Seen))
+	for _, id := range state.Seen {
+		seen[id] = true
+	}
+	queue := make([]NoteFile, 0, len(notes))
+	for _, item := range notes {
+		if !seen[item.Note.ID] {
+			queue = append(queue, item)
+		}
+	}
+	SortNotes(queue, "created", true)
+	return queue, nil
+}
+
+func (s *Service) Vocabulary() ([]string, []string, error) {
+	notes, err := vault.ListNotes(s.root, NoteFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	domains, tags := map[string]bool{}, map[string]bool{}
+	for _, item := range notes {
+		if item.Note.Domain != "" {
+			domains[item.Note.Domain] = true
+		}
+		for _, tag := range item.Note.Tags {
+			tags[tag] = true
+		}
+	}
+	if entries, err := os.ReadDir(filepath.Join(s.root, "notes", "domains")); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && vault.IsValidDomainID(entry.Name()) {
+				domains[entry.Name()] = true
+			}
+		}
+	}
+	return sortedKeys(domains), sortedKeys(tags), nil
+}
+
+func sortedKeys(values map[string]bool) []string {
+	out := make([]string, 0, len(values))
+	for value := range values {
+		out = append(out, value)
+	}
+	sort.Strings(out)
+	return out
+}
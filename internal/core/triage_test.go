@@ -0,0 +1,67 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"nitid/internal/vault"
+)
+
+func TestTriageQueueAndState(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	svc, notes := newTestService(t,
+		Note{Title: "Newest", Status: vault.StatusInbox, CreatedAt: base.Add(2 * time.Hour), Tags: []string{"go"}},
+		Note{Title: "Oldest", Status: vault.StatusInbox, CreatedAt: base},
+		Note{Title: "Filed", Status: vault.StatusActive, Domain: "ops", CreatedAt: base.Add(time.Hour), Tags: []string{"ci"}},
+	)
+	ids := map[string]string{}
+	for _, note := range notes {
+		ids[note.Title] = note.ID
+	}
+
+	state, resumed, err := svc.LoadTriage()
+	if err != nil || resumed {
+		t.Fatalf("expected no saved triage, got resumed=%v err=%v", resumed, err)
+	}
+	queue, err := svc.TriageQueue(state)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	if len(queue) != 2 || queue[0].Note.Title != "Oldest" || queue[1].Note.Title != "Newest" {
+		t.Fatalf("unexpected queue: %+v", queue)
+	}
+
+	state.Started = base
+	state.MarkSeen(ids["Oldest"])
+	state.Skipped++
+	if err := svc.SaveTriage(state); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, resumed, err := svc.LoadTriage()
+	if err != nil || !resumed || !reflect.DeepEqual(loaded, state) {
+		t.Fatalf("unexpected loaded state: %+v resumed=%v err=%v", loaded, resumed, err)
+	}
+	queue, err = svc.TriageQueue(loaded)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	if len(queue) != 1 || queue[0].Note.Title != "Newest" {
+		t.Fatalf("expected seen notes to be skipped, got %+v", queue)
+	}
+
+	if err := svc.ClearTriage(); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if _, resumed, _ := svc.LoadTriage(); resumed {
+		t.Fatalf("expected triage progress to be cleared")
+	}
+
+	domains, tags, err := svc.Vocabulary()
+	if err != nil {
+		t.Fatalf("vocabulary: %v", err)
+	}
+	if !reflect.DeepEqual(domains, []string{"ops"}) || !reflect.DeepEqual(tags, []string{"ci", "go"}) {
+		t.Fatalf("unexpected vocabulary: %v %v", domains, tags)
+	}
+}
@@ -0,0 +1,133 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+type Config struct {
+	Version         int
+	DefaultDomain   string
+	DefaultKind     string
+	Timezone        string
+	OutputTemplates map[string]string
+	values          map[string]any
+}
+
+func configPath(root string) string {
+	return filepath.Join(root, ".nitid", "config.toml")
+}
+
+func loadConfig(root string) (Config, error) {
+	cfg := Config{
+		Version:         1,
+		DefaultKind:     "note",
+		OutputTemplates: map[string]string{},
+		values:          map[string]any{},
+	}
+
+	b, err := os.ReadFile(configPath(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return Config{}, err
+	}
+
+	values, err := parseConfig(string(b))
+	if err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", filepath.ToSlash(filepath.Join(".nitid", "config.toml")), err)
+	}
+	cfg.values = values
+
+	if v, ok := values["vault.version"].(int64); ok {
+		cfg.Version = int(v)
+	}
+	cfg.DefaultDomain = cfg.String("vault.default_domain", "")
+	cfg.DefaultKind = cfg.String("vault.default_kind", cfg.DefaultKind)
+	cfg.Timezone = strings.TrimSpace(cfg.String("vault.timezone", ""))
+	cfg.OutputTemplates = cfg.Section("output.templates")
+
+	return cfg, nil
+}
+
+func (c Config) String(key, fallback string) string {
+	if v, ok := c.values[key].(string); ok {
+		return v
+	}
+	return fallback
+}
+
+func (c Config) Strings(key string, fallback []string) []string {
+	if v, ok := c.values[key].([]string); ok {
+		return v
+	}
+	if v, ok := c.values[key].(string); ok {
+		return []string{v}
+	}
+	return fallback
+}
+
+func (c Config) Section(prefix string) map[string]string {
+	out := map[string]string{}
+	prefix = strings.TrimSuffix(prefix, ".") + "."
+	for key, value := range c.values {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok || strings.Contains(name, ".") {
+			continue
+		}
+		if text, isString := value.(string); isString {
+			out[name] = text
+		}
+	}
+	return out
+}
+
+func parseConfig(content string) (map[string]any, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(content, &doc); err != nil {
+		return nil, err
+	}
+	values := map[string]any{}
+	flattenConfig("", doc, values)
+	return values, nil
+}
+
+func flattenConfig(prefix string, table map[string]any, values map[string]any) {
+	for key, value := range table {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flattenConfig(fullKey, v, values)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				text, ok := item.(string)
+				if !ok {
+					items = nil
+					break
+				}
+				items = append(items, text)
+			}
+			if items != nil {
+				values[fullKey] = items
+			} else {
+				values[fullKey] = v
+			}
+		default:
+			values[fullKey] = v
+		}
+	}
+}
+
+func LoadConfig(root string) (Config, error) {
+	return loadConfig(root)
+}
@@ -8,11 +8,13 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/oklog/ulid/v2"
 	"gopkg.in/yaml.v3"
@@ -29,6 +31,8 @@ const (
 	statusArchived = "archived"
 )
 
+var ErrNoteNotFound = errors.New("not found")
+
 var allowedStatuses = map[string]struct{}{
 	statusInbox:    {},
 	statusActive:   {},
@@ -46,6 +50,8 @@ type Note struct {
 	Kind      string
 	Links     []string
 	Body      string
+	Extra     map[string]any
+	extraNode *yaml.Node
 }
 
 type NoteFile struct {
@@ -55,10 +61,20 @@ type NoteFile struct {
 }
 
 type NoteFilter struct {
-	Domain string
-	Tag    string
-	Status string
-	Kind   string
+	Domain        string
+	Tag           string
+	Tags          []string
+	AnyTags       []string
+	NotTags       []string
+	Status        string
+	Kind          string
+	NotDomain     string
+	NotStatus     string
+	NotKind       string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
 }
 
 type noteFrontmatter struct {
@@ -73,6 +89,18 @@ type noteFrontmatter struct {
 	Links     []string `yaml:"links"`
 }
 
+var knownFrontmatterKeys = map[string]struct{}{
+	"id":         {},
+	"title":      {},
+	"created_at": {},
+	"updated_at": {},
+	"domain":     {},
+	"tags":       {},
+	"status":     {},
+	"kind":       {},
+	"links":      {},
+}
+
 func createVaultStructure(root string) error {
 	dirs := []string{
 		filepath.Join(root, "notes", "inbox"),
@@ -106,11 +134,14 @@ func writeNote(root string, note Note) (string, error) {
 		return "", err
 	}
 
+	content, err := renderMarkdown(note)
+	if err != nil {
+		return "", err
+	}
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return "", err
 	}
 
-	content := renderMarkdown(note)
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		return "", err
 	}
@@ -132,7 +163,7 @@ func normalizeStatus(note Note) string {
 }
 
 func deriveStatus(note Note) string {
-	if note.Kind == "daily" {
+	if isPeriodicKind(note.Kind) {
 		return statusActive
 	}
 	if strings.TrimSpace(note.Domain) == "" {
@@ -150,6 +181,15 @@ func resolveNotePath(root string, note Note) (string, error) {
 		return filepath.Join(root, "notes", "daily", y, m, fileName), nil
 	}
 
+	if note.Kind == "weekly" {
+		y, _ := note.CreatedAt.ISOWeek()
+		return filepath.Join(root, "notes", "weekly", fmt.Sprintf("%04d", y), fileName), nil
+	}
+
+	if note.Kind == "monthly" || note.Kind == "quarterly" {
+		return filepath.Join(root, "notes", note.Kind, note.CreatedAt.Format("2006"), fileName), nil
+	}
+
 	if note.Status == statusArchived {
 		return filepath.Join(root, "notes", "archive", fileName), nil
 	}
@@ -202,11 +242,15 @@ func saveNote(root string, currentPath string, note Note) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	content, err := renderMarkdown(note)
+	if err != nil {
+		return "", err
+	}
 	if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
 		return "", err
 	}
 
-	if err := os.WriteFile(newPath, []byte(renderMarkdown(note)), 0o644); err != nil {
+	if err := os.WriteFile(newPath, []byte(content), 0o644); err != nil {
 		return "", err
 	}
 
@@ -297,10 +341,21 @@ func matchesFilter(note Note, filter NoteFilter) bool {
 	if filter.Domain != "" && note.Domain != filter.Domain {
 		return false
 	}
-	if filter.Tag != "" {
+	if filter.NotDomain != "" && note.Domain == filter.NotDomain {
+		return false
+	}
+	if filter.Tag != "" && !hasTag(note.Tags, filter.Tag) {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !hasTag(note.Tags, tag) {
+			return false
+		}
+	}
+	if len(filter.AnyTags) > 0 {
 		found := false
-		for _, tag := range note.Tags {
-			if tag == filter.Tag {
+		for _, tag := range filter.AnyTags {
+			if hasTag(note.Tags, tag) {
 				found = true
 				break
 			}
@@ -309,12 +364,48 @@ func matchesFilter(note Note, filter NoteFilter) bool {
 			return false
 		}
 	}
+	for _, tag := range filter.NotTags {
+		if hasTag(note.Tags, tag) {
+			return false
+		}
+	}
 	if filter.Status != "" && note.Status != filter.Status {
 		return false
 	}
+	if filter.NotStatus != "" && note.Status == filter.NotStatus {
+		return false
+	}
 	if filter.Kind != "" && note.Kind != filter.Kind {
 		return false
 	}
+	if filter.NotKind != "" && note.Kind == filter.NotKind {
+		return false
+	}
+	if !withinRange(note.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) {
+		return false
+	}
+	if !withinRange(note.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+func hasTag(tags []string, want string) bool {
+	for _, tag := range tags {
+		if tag == want {
+			return true
+		}
+	}
+	return false
+}
+
+func withinRange(value, after, before time.Time) bool {
+	if !after.IsZero() && value.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !value.Before(before) {
+		return false
+	}
 	return true
 }
 
@@ -354,7 +445,7 @@ func findNoteByID(root, id string) (NoteFile, error) {
 		return NoteFile{}, fmt.Errorf("multiple notes match prefix %q", id)
 	}
 
-	return NoteFile{}, fmt.Errorf("note %q not found", id)
+	return NoteFile{}, fmt.Errorf("note %q %w", id, ErrNoteNotFound)
 }
 
 func findNoteBySelector(root, selector string) (NoteFile, error) {
@@ -434,6 +525,10 @@ func readNote(path string) (Note, error) {
 	if err := yaml.Unmarshal(fmRaw, &fm); err != nil {
 		return Note{}, fmt.Errorf("parse frontmatter in %s: %w", path, err)
 	}
+	extra, extraNode, err := extraFrontmatter(fmRaw)
+	if err != nil {
+		return Note{}, fmt.Errorf("parse frontmatter in %s: %w", path, err)
+	}
 
 	createdAt, err := parseRFC3339Field("created_at", fm.CreatedAt)
 	if err != nil {
@@ -455,6 +550,8 @@ func readNote(path string) (Note, error) {
 		Kind:      strings.TrimSpace(fm.Kind),
 		Links:     fm.Links,
 		Body:      strings.TrimSpace(body),
+		Extra:     extra,
+		extraNode: extraNode,
 	}
 
 	if err := validateNoteForWrite(note); err != nil {
@@ -465,6 +562,85 @@ func readNote(path string) (Note, error) {
 	return note, nil
 }
 
+func extraFrontmatter(fmRaw []byte) (map[string]any, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(fmRaw, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, nil
+	}
+
+	var extra map[string]any
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	pairs := doc.Content[0].Content
+	for i := 0; i+1 < len(pairs); i += 2 {
+		key := pairs[i].Value
+		if _, known := knownFrontmatterKeys[key]; known {
+			continue
+		}
+		value, err := yamlNodeValue(pairs[i+1])
+		if err != nil {
+			return nil, nil, err
+		}
+		if extra == nil {
+			extra = map[string]any{}
+		}
+		extra[key] = value
+		node.Content = append(node.Content, pairs[i], pairs[i+1])
+	}
+	return extra, node, nil
+}
+
+func yamlNodeValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!timestamp" {
+			return node.Value, nil
+		}
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			item, err := yamlNodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case yaml.MappingNode:
+		fields := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			field, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			fields[node.Content[i].Value] = field
+		}
+		return fields, nil
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func bodyLineOffset(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	fm, body, err := splitFrontmatter(b)
+	if err != nil {
+		return 0, err
+	}
+	leading := body[:len(body)-len(strings.TrimLeftFunc(body, unicode.IsSpace))]
+	return bytes.Count(fm, []byte("\n")) + 3 + strings.Count(leading, "\n"), nil
+}
+
 func splitFrontmatter(content []byte) ([]byte, string, error) {
 	normalized := bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
 	if !bytes.HasPrefix(normalized, []byte("---\n")) {
@@ -513,8 +689,24 @@ func sanitizeTags(tags []string) []string {
 	return clean
 }
 
+func normalizeTag(raw string) string {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "#")
+	if raw == "" {
+		return ""
+	}
+	tag := slugify(raw)
+	if tag == "untitled" && !strings.Contains(strings.ToLower(raw), "untitled") {
+		return ""
+	}
+	return tag
+}
+
 func isAllowedKind(kind string) bool {
-	return kind == "note" || kind == "adr" || kind == "snippet" || kind == "daily"
+	return kind == "note" || kind == "adr" || kind == "snippet" || isPeriodicKind(kind)
+}
+
+func isPeriodicKind(kind string) bool {
+	return kind == "daily" || kind == "weekly" || kind == "monthly" || kind == "quarterly"
 }
 
 func parseCSV(value string) []string {
@@ -572,7 +764,7 @@ func slugify(input string) string {
 	return slug
 }
 
-func renderMarkdown(note Note) string {
+func renderMarkdown(note Note) (string, error) {
 	note.Status = normalizeStatus(note)
 	note.Tags = sanitizeTags(note.Tags)
 	var b strings.Builder
@@ -586,10 +778,93 @@ func renderMarkdown(note Note) string {
 	b.WriteString(fmt.Sprintf("status: \"%s\"\n", note.Status))
 	b.WriteString(fmt.Sprintf("kind: \"%s\"\n", note.Kind))
 	b.WriteString(fmt.Sprintf("links: %s\n", renderInlineList(note.Links)))
+	extra, err := renderExtraFrontmatter(note.Extra, note.extraNode)
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(extra)
 	b.WriteString("---\n\n")
 	b.WriteString(note.Body)
 	b.WriteString("\n")
-	return b.String()
+	return b.String(), nil
+}
+
+func renderExtraFrontmatter(extra map[string]any, original *yaml.Node) (string, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	written := map[string]bool{}
+	if original != nil {
+		for i := 0; i+1 < len(original.Content); i += 2 {
+			key := original.Content[i].Value
+			value, ok := extra[key]
+			if !ok {
+				continue
+			}
+			if previous, err := yamlNodeValue(original.Content[i+1]); err == nil && reflect.DeepEqual(previous, value) {
+				node.Content = append(node.Content, original.Content[i], original.Content[i+1])
+				written[key] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		if _, known := knownFrontmatterKeys[key]; known || strings.TrimSpace(key) == "" || written[key] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := encodeYAMLNode(extra[key])
+		if err != nil {
+			return "", fmt.Errorf("render extra frontmatter: %s: %w", key, err)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	}
+	if len(node.Content) == 0 {
+		return "", nil
+	}
+	out, err := marshalYAML(node)
+	if err != nil {
+		return "", fmt.Errorf("render extra frontmatter: %w", err)
+	}
+	return string(out), nil
+}
+
+func encodeYAMLNode(value any) (node *yaml.Node, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	node = &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	markTimestamps(node)
+	return node, nil
+}
+
+func markTimestamps(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str" {
+		var probe yaml.Node
+		if yaml.Unmarshal([]byte(node.Value), &probe) == nil && len(probe.Content) == 1 && probe.Content[0].ShortTag() == "!!timestamp" {
+			node.Tag, node.Style = "!!timestamp", 0
+		}
+		return
+	}
+	for _, child := range node.Content {
+		markTimestamps(child)
+	}
+}
+
+func marshalYAML(value any) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return yaml.Marshal(value)
 }
 
 func renderInlineList(items []string) string {
@@ -616,6 +891,26 @@ func defaultConfig() string {
 version = 1
 default_domain = ""
 default_kind = "note"
+# timezone = "America/Los_Angeles"
+
+[output.templates]
+# wiki = "- [[{{.ID}}]] {{.Title}} ({{.Domain}})"
+
+[import.obsidian]
+# daily_folders = ["Daily", "Daily Notes", "Journal"]
+# daily_format = "YYYY-MM-DD"
+# domain_from = "folder"
+# nested_tags = "flatten"
+# title_from = "filename"
+# skip_folders = ["Templates"]
+
+[daily]
+# carry_over = "copy"
+
+[periodic]
+# weekly_template = "weekly"
+# monthly_template = "monthly"
+# quarterly_template = "quarterly"
 `) + "\n"
 }
 
@@ -654,6 +949,10 @@ func ListNotes(root string, filter NoteFilter) ([]NoteFile, error) {
 	return listNotes(root, filter)
 }
 
+func BodyLineOffset(path string) (int, error) {
+	return bodyLineOffset(path)
+}
+
 func ReadNote(path string) (Note, error) {
 	return readNote(path)
 }
@@ -686,10 +985,26 @@ func IsAllowedKind(kind string) bool {
 	return isAllowedKind(strings.ToLower(strings.TrimSpace(kind)))
 }
 
+func IsPeriodicKind(kind string) bool {
+	return isPeriodicKind(kind)
+}
+
 func ParseCSV(value string) []string {
 	return parseCSV(value)
 }
 
+func SanitizeTags(tags []string) []string {
+	return sanitizeTags(tags)
+}
+
+func NormalizeTag(raw string) string {
+	return normalizeTag(raw)
+}
+
+func Slugify(input string) string {
+	return slugify(input)
+}
+
 func NewULID(now time.Time) string {
 	return newULID(now)
 }
@@ -1,7 +1,9 @@
 package vault
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -90,6 +92,11 @@ func TestSaveAndReadNoteRoundtrip(t *testing.T) {
 		Kind:      "note",
 		Links:     []string{},
 		Body:      "hello world",
+		Extra: map[string]any{
+			"source": "https://example.com",
+			"rating": 4,
+			"id":     "ignored",
+		},
 	}
 
 	rel, err := saveNote(root, "", note)
@@ -108,6 +115,66 @@ func TestSaveAndReadNoteRoundtrip(t *testing.T) {
 	if loaded.Body != note.Body {
 		t.Fatalf("body mismatch: got=%q want=%q", loaded.Body, note.Body)
 	}
+	if len(loaded.Extra) != 2 || loaded.Extra["source"] != "https://example.com" || loaded.Extra["rating"] != 4 {
+		t.Fatalf("extra frontmatter mismatch: got=%v", loaded.Extra)
+	}
+
+	loaded.Extra["callback"] = func() {}
+	if _, err := saveNote(root, filepath.Join(root, rel), loaded); err == nil {
+		t.Fatalf("expected error for unencodable extra frontmatter")
+	}
+	kept, err := readNote(filepath.Join(root, rel))
+	if err != nil {
+		t.Fatalf("read note after failed save: %v", err)
+	}
+	if kept.Extra["source"] != "https://example.com" {
+		t.Fatalf("failed save should leave the note untouched: got=%v", kept.Extra)
+	}
+}
+
+func TestSaveNoteKeepsExtraFrontmatterText(t *testing.T) {
+	root := t.TempDir()
+	if err := createVaultStructure(root); err != nil {
+		t.Fatalf("create vault: %v", err)
+	}
+
+	path := filepath.Join(root, "notes", "inbox", "01JNA0000000000000000000T1--dates.md")
+	raw := "---\nid: \"01JNA0000000000000000000T1\"\ntitle: \"Dates\"\ncreated_at: \"2026-03-01T09:00:00Z\"\nupdated_at: \"2026-03-01T09:00:00Z\"\ndomain: \"\"\ntags: []\nstatus: \"inbox\"\nkind: \"note\"\nlinks: []\nreview: 2026-10-20 # quarterly\nratio: 1.50\ndue: 2026-10-20\n---\n\nbody\n"
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+
+	note, err := readNote(path)
+	if err != nil {
+		t.Fatalf("read note: %v", err)
+	}
+	if note.Extra["due"] != "2026-10-20" || note.Extra["review"] != "2026-10-20" {
+		t.Fatalf("dates should keep their text: %v", note.Extra)
+	}
+
+	note.Tags = []string{"planning"}
+	if _, err := saveNote(root, path, note); err != nil {
+		t.Fatalf("save note: %v", err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read saved note: %v", err)
+	}
+	if !strings.Contains(string(saved), "links: []\nreview: 2026-10-20 # quarterly\nratio: 1.50\ndue: 2026-10-20\n---") {
+		t.Fatalf("extra frontmatter not kept:\n%s", saved)
+	}
+
+	note.Extra = map[string]any{"due": "2026-11-02", "ratio": 1.5, "owner": "ana"}
+	if _, err := saveNote(root, path, note); err != nil {
+		t.Fatalf("save note: %v", err)
+	}
+	saved, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read saved note: %v", err)
+	}
+	if !strings.Contains(string(saved), "links: []\nratio: 1.50\ndue: 2026-11-02\nowner: ana\n---") {
+		t.Fatalf("changed extra frontmatter not written:\n%s", saved)
+	}
 }
 
 func TestFindNoteBySelectorWithAtRef(t *testing.T) {
@@ -153,3 +220,170 @@ func TestFindNoteBySelectorWithAtRef(t *testing.T) {
 		t.Fatalf("@1 should be most recently updated note: got=%s want=%s", selected.Note.ID, noteB.ID)
 	}
 }
+
+func TestMatchesFilterDateRange(t *testing.T) {
+	created := time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC)
+	note := Note{
+		ID:        "01JN8PX5WP8J67JAY2P2CVJH6D",
+		Title:     "Dated note",
+		CreatedAt: created,
+		UpdatedAt: created.AddDate(0, 0, 5),
+		Kind:      "note",
+	}
+
+	tests := []struct {
+		name   string
+		filter NoteFilter
+		want   bool
+	}{
+		{name: "no bounds", filter: NoteFilter{}, want: true},
+		{name: "created after inclusive", filter: NoteFilter{CreatedAfter: created}, want: true},
+		{name: "created after later", filter: NoteFilter{CreatedAfter: created.Add(time.Hour)}, want: false},
+		{name: "created before exclusive", filter: NoteFilter{CreatedBefore: created}, want: false},
+		{name: "created before later", filter: NoteFilter{CreatedBefore: created.AddDate(0, 0, 1)}, want: true},
+		{name: "updated window", filter: NoteFilter{UpdatedAfter: created, UpdatedBefore: created.AddDate(0, 0, 6)}, want: true},
+		{name: "updated too early", filter: NoteFilter{UpdatedBefore: created.AddDate(0, 0, 2)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesFilter(note, tt.filter); got != tt.want {
+				t.Fatalf("matchesFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFilterTagSetsAndNegation(t *testing.T) {
+	note := Note{
+		Domain: "engineering",
+		Tags:   []string{"concurrency", "go"},
+		Status: statusActive,
+		Kind:   "note",
+	}
+
+	tests := []struct {
+		name   string
+		filter NoteFilter
+		want   bool
+	}{
+		{name: "all of present", filter: NoteFilter{Tags: []string{"go", "concurrency"}}, want: true},
+		{name: "all of missing one", filter: NoteFilter{Tags: []string{"go", "k8s"}}, want: false},
+		{name: "any of hit", filter: NoteFilter{AnyTags: []string{"k8s", "go"}}, want: true},
+		{name: "any of miss", filter: NoteFilter{AnyTags: []string{"k8s", "docker"}}, want: false},
+		{name: "none of hit", filter: NoteFilter{NotTags: []string{"draft", "go"}}, want: false},
+		{name: "none of miss", filter: NoteFilter{NotTags: []string{"draft"}}, want: true},
+		{name: "not domain", filter: NoteFilter{NotDomain: "engineering"}, want: false},
+		{name: "not status", filter: NoteFilter{NotStatus: statusArchived}, want: true},
+		{name: "not kind", filter: NoteFilter{NotKind: "note"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesFilter(note, tt.filter); got != tt.want {
+				t.Fatalf("matchesFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	content := `
+# comment
+[vault]
+version = 2
+default_domain = "engineering" # trailing comment
+
+[output.templates]
+wiki = "- [[{{.ID}}]] {{.Title}} # not a comment"
+raw = '{{.RelPath}}'
+`
+	values, err := parseConfig(content)
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	if values["vault.version"] != int64(2) {
+		t.Fatalf("unexpected version: %#v", values["vault.version"])
+	}
+	if values["vault.default_domain"] != "engineering" {
+		t.Fatalf("unexpected default domain: %#v", values["vault.default_domain"])
+	}
+	if values["output.templates.wiki"] != "- [[{{.ID}}]] {{.Title}} # not a comment" {
+		t.Fatalf("unexpected wiki template: %#v", values["output.templates.wiki"])
+	}
+	if values["output.templates.raw"] != "{{.RelPath}}" {
+		t.Fatalf("unexpected raw template: %#v", values["output.templates.raw"])
+	}
+
+	values, err = parseConfig(`
+vault.default_kind = "adr"
+output.templates = { short = '{{.ID}}', "dotted.name" = "x" }
+
+[import.obsidian]
+daily_folders = [
+  "Daily",
+  'Journal',
+]
+title_from = 'C:\notes\{title}'
+limits = [1, 2]
+`)
+	if err != nil {
+		t.Fatalf("parse config with inline tables and multi-line arrays: %v", err)
+	}
+	cfg := Config{values: values}
+	if got := cfg.String("vault.default_kind", ""); got != "adr" {
+		t.Fatalf("unexpected dotted key value: %q", got)
+	}
+	if got := cfg.Section("output.templates"); len(got) != 1 || got["short"] != "{{.ID}}" {
+		t.Fatalf("unexpected inline table section: %#v", got)
+	}
+	if got := cfg.Strings("import.obsidian.daily_folders", nil); len(got) != 2 || got[1] != "Journal" {
+		t.Fatalf("unexpected multi-line array: %#v", got)
+	}
+	if got := cfg.String("import.obsidian.title_from", ""); got != `C:\notes\{title}` {
+		t.Fatalf("unexpected literal string: %q", got)
+	}
+	if got := cfg.Strings("import.obsidian.limits", []string{"fallback"}); len(got) != 1 || got[0] != "fallback" {
+		t.Fatalf("expected non-string arrays to fall back, got %#v", got)
+	}
+
+	if _, err := parseConfig("[vault\nversion = 1"); err == nil {
+		t.Fatalf("expected error for invalid section")
+	}
+	if _, err := parseConfig("[vault]\nversion"); err == nil {
+		t.Fatalf("expected error for missing value")
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	root := t.TempDir()
+	cfg, err := loadConfig(root)
+	if err != nil {
+		t.Fatalf("load missing config: %v", err)
+	}
+	if cfg.DefaultKind != "note" || len(cfg.OutputTemplates) != 0 {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+
+	if err := createVaultStructure(root); err != nil {
+		t.Fatalf("create vault: %v", err)
+	}
+	if _, err := loadConfig(root); err != nil {
+		t.Fatalf("load default config: %v", err)
+	}
+}
+
+func TestBodyLineOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+	content := "---\nid: x\ntitle: \"T\"\n---\n\n\nfirst body line\nsecond\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	offset, err := bodyLineOffset(path)
+	if err != nil {
+		t.Fatalf("offset: %v", err)
+	}
+	if offset != 6 {
+		t.Fatalf("expected body to start after 6 lines, got %d", offset)
+	}
+}